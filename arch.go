@@ -0,0 +1,44 @@
+package java
+
+import "runtime"
+
+// archNames maps the architecture names used by Go and by the os.arch
+// property of the various JVMs to the normalized name used by JVMs on
+// Linux (see HostArch).
+var archNames = map[string]string{
+	"amd64":   "amd64",
+	"x86_64":  "amd64",
+	"arm64":   "aarch64",
+	"aarch64": "aarch64",
+	"386":     "x86",
+	"i386":    "x86",
+	"i686":    "x86",
+	"x86":     "x86",
+}
+
+// normalizeArch returns the normalized name for arch (or arch itself if
+// it has no other name such as ppc64le, s390x, and riscv64).
+func normalizeArch(arch string) string {
+	if name, has := archNames[arch]; has {
+		return name
+	}
+	return arch
+}
+
+// HostArch returns the normalized architecture ("amd64", "aarch64",
+// "x86", and so on) of this Go program (runtime.GOARCH) using the same
+// names as JVMArch so that they can be compared directly.
+func HostArch() string { return normalizeArch(runtime.GOARCH) }
+
+// JVMArch returns the normalized architecture (see HostArch) of the
+// java on the system path (os.arch). When it does not match HostArch
+// one of them is running under emulation (such as Rosetta 2) which
+// matters when choosing which native libraries to extract. The result
+// is cached for each java executable (see SystemProperties).
+func JVMArch() (string, error) {
+	props, err := cachedProperties()
+	if err != nil {
+		return "", err
+	}
+	return normalizeArch(props["os.arch"]), nil
+}
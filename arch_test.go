@@ -0,0 +1,21 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleJVMArch() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/fakejava")
+	os.Setenv("PATH", bin)
+
+	fmt.Println(java.JVMArch())
+
+	// Output:
+	// amd64 <nil>
+}
@@ -0,0 +1,94 @@
+package java
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	_fs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rwxrob/fs"
+)
+
+// ArchiveCache writes a zip archive of everything in the CacheDir to w
+// so that a known-good extracted cache can be restored on another
+// system with RestoreCache (skipping extraction).
+func ArchiveCache(w io.Writer) error {
+	z := zip.NewWriter(w)
+	err := filepath.WalkDir(CacheDir,
+		func(path string, d _fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(CacheDir, path)
+			if err != nil {
+				return err
+			}
+			f, err := z.Create(filepath.ToSlash(rel))
+			if err != nil {
+				return err
+			}
+			buf, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			_, err = f.Write(buf)
+			return err
+		})
+	if err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// RestoreCache unpacks a zip archive (see ArchiveCache) read from r into
+// the CacheDir (updating the CLASSPATH as Extract does). Any entry that
+// would be written outside of the CacheDir (zip slip) causes an error
+// wrapping ErrOutsideCache before anything is written.
+func RestoreCache(r io.Reader) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	z, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		return err
+	}
+	for _, f := range z.File {
+		to := filepath.Join(CacheDir, filepath.FromSlash(f.Name))
+		rel, err := filepath.Rel(CacheDir, to)
+		if err != nil || filepath.IsAbs(f.Name) || rel == ".." ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%v: %w", f.Name, ErrOutsideCache)
+		}
+	}
+	for _, f := range z.File {
+		to := filepath.Join(CacheDir, filepath.FromSlash(f.Name))
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(to, fs.ExtractDirPerms); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(to), fs.ExtractDirPerms); err != nil {
+			return err
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(to, data, fs.ExtractFilePerms); err != nil {
+			return err
+		}
+	}
+	updateCP()
+	return nil
+}
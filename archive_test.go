@@ -0,0 +1,49 @@
+package java_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleArchiveCache() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	if err := java.Extract(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := java.ArchiveCache(buf); err != nil {
+		fmt.Println(err)
+	}
+
+	java.CacheDir = "testdata/tmpcache2"
+	defer os.RemoveAll("testdata/tmpcache2")
+	if err := java.RestoreCache(buf); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(java.Cached("HelloWorld.class"))
+
+	// Output:
+	// testdata/tmpcache2/HelloWorld.class
+}
+
+func ExampleRestoreCache_zipslip() {
+
+	buf := new(bytes.Buffer)
+	z := zip.NewWriter(buf)
+	z.Create("../../evil.sh")
+	z.Close()
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	fmt.Println(java.RestoreCache(buf))
+
+	// Output:
+	// ../../evil.sh: path is outside of cache
+}
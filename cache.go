@@ -0,0 +1,402 @@
+package java
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	_fs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rwxrob/fs"
+)
+
+// CachedJarsClasspath returns the paths of every jar file anywhere
+// within the CacheDir (in lexical order) joined with the
+// os.PathListSeparator ready to be passed as the value of -cp. An empty
+// string is returned if there are none.
+func CachedJarsClasspath() (string, error) {
+	var jars []string
+	err := filepath.WalkDir(CacheDir,
+		func(path string, d _fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, ".jar") {
+				jars = append(jars, path)
+			}
+			return nil
+		})
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(jars, string(filepath.ListSeparator)), nil
+}
+
+type extractKey struct {
+	fsys  embed.FS
+	root  string
+	cache string
+}
+
+type extraction struct {
+	once sync.Once
+	err  error
+}
+
+var (
+	extractions   = map[extractKey]*extraction{}
+	extractionsMu sync.Mutex
+)
+
+// EnsureExtracted calls Extract at most once per process for any given
+// embedded file system, root, and CacheDir combination returning the
+// error (if any) of that first extraction on every call. This makes it
+// cheap to call defensively before every run. Extract remains the
+// variant that always extracts.
+func EnsureExtracted(fsys embed.FS, root string) error {
+	key := extractKey{fsys, root, CacheDir}
+	extractionsMu.Lock()
+	x, has := extractions[key]
+	if !has {
+		x = new(extraction)
+		extractions[key] = x
+	}
+	extractionsMu.Unlock()
+	x.once.Do(func() { x.err = Extract(fsys, root) })
+	return x.err
+}
+
+// CacheKey returns a hex encoded SHA-256 digest of the relative paths
+// and contents of every file under root in the embedded file system
+// (in lexical order). Since it changes whenever any embedded content
+// does it can be used to give each embedded build its own CacheDir:
+//
+//	java.CacheDir = filepath.Join(java.CacheDir, key)
+func CacheKey(fsys embed.FS, root string) (string, error) {
+	h := sha256.New()
+	err := _fs.WalkDir(fsys, root,
+		func(path string, d _fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			buf, err := fsys.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			h.Write([]byte(strings.TrimPrefix(path, root)))
+			h.Write([]byte{0})
+			h.Write(buf)
+			h.Write([]byte{0})
+			return nil
+		})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CacheCurrent returns true only if every file under root in the
+// embedded file system has already been extracted (see Extract) into
+// the CacheDir (or SystemCacheDir) with the same content (compared by
+// SHA-256 digest). Tools can use this to decide whether to show an
+// "updating" message before calling Extract.
+func CacheCurrent(fsys embed.FS, root string) (bool, error) {
+	if err := checkRoot(fsys, root); err != nil {
+		return false, err
+	}
+	stale := errors.New("stale")
+	err := _fs.WalkDir(fsys, root,
+		func(path string, d _fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			fresh, err := isFresh(fsys, path, strings.TrimPrefix(path, root))
+			if err != nil {
+				return err
+			}
+			if !fresh {
+				return stale
+			}
+			return nil
+		})
+	if err == stale {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// isFresh returns true if the embedded file at path has the same
+// content as rel within the CacheDir or SystemCacheDir.
+func isFresh(fsys embed.FS, path, rel string) (bool, error) {
+	buf, err := fsys.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	sum := sha256.Sum256(buf)
+	for _, dir := range []string{CacheDir, SystemCacheDir} {
+		if dir == "" {
+			continue
+		}
+		cached, err := os.ReadFile(filepath.Join(dir, rel))
+		if err == nil && sha256.Sum256(cached) == sum {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// TouchCache sets the access and modification times of every file and
+// directory in the CacheDir to t. Since embedded files have no
+// modification time, extracted files otherwise get the time of
+// extraction making modification-time comparisons and reproducible
+// cache directories impossible.
+func TouchCache(t time.Time) error {
+	return filepath.WalkDir(CacheDir,
+		func(path string, d _fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			return os.Chtimes(path, t, t)
+		})
+}
+
+// WithTempCache extracts everything under root within fsys into a new
+// temporary directory (within TempDir) and calls fn with its path
+// which is removed when fn returns (whatever the result). Neither
+// CacheDir nor CLASSPATH is changed so fn should pass the directory
+// explicitly (ex: Exec("-cp", dir, "foo.Main")). This provides
+// a hermetic way to run embedded classes (usually from tests) without
+// touching the shared cache.
+func WithTempCache(fsys embed.FS, root string, fn func(cacheDir string) error) error {
+	dir, err := mkdirTemp("java-cache-*")
+	if err != nil {
+		return err
+	}
+	defer removeTemp(dir)
+	if err := extractTree(fsys, root, dir, "", nil); err != nil {
+		return err
+	}
+	return fn(dir)
+}
+
+// ListEmbedded returns the paths (relative to root and in lexical
+// order) of every file under root within fsys, which are exactly the
+// paths within the CacheDir that Extract would write, without
+// extracting anything.
+func ListEmbedded(fsys embed.FS, root string) ([]string, error) {
+	if err := checkRoot(fsys, root); err != nil {
+		return nil, err
+	}
+	var files []string
+	err := _fs.WalkDir(fsys, root,
+		func(path string, d _fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			files = append(files, strings.TrimPrefix(strings.TrimPrefix(path, root), "/"))
+			return nil
+		})
+	return files, err
+}
+
+// EmbedRoot is an embedded file system and the root within it to be
+// extracted (see ExtractAll).
+type EmbedRoot struct {
+	FS   embed.FS
+	Root string
+}
+
+// ExtractAll is the same as Extract but merges several embedded roots
+// (usually from separate packages each embedding a subtree) into the
+// one cache layout. Files with the same relative path in more than one
+// root are only extracted once if identical. If any differ nothing is
+// extracted and an error listing every conflicting path is returned.
+func ExtractAll(roots ...EmbedRoot) error {
+	type source struct {
+		fsys embed.FS
+		path string
+		sum  [sha256.Size]byte
+	}
+	files := map[string]source{}
+	var rels, conflicts []string
+	for _, r := range roots {
+		if err := checkRoot(r.FS, r.Root); err != nil {
+			return err
+		}
+		err := _fs.WalkDir(r.FS, r.Root, func(path string, d _fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			buf, err := r.FS.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel := strings.TrimPrefix(strings.TrimPrefix(path, r.Root), "/")
+			src := source{r.FS, path, sha256.Sum256(buf)}
+			prev, seen := files[rel]
+			switch {
+			case !seen:
+				files[rel] = src
+				rels = append(rels, rel)
+			case prev.sum != src.sum:
+				conflicts = append(conflicts, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("conflicting embedded files: %v", conflicts)
+	}
+	for _, rel := range rels {
+		src := files[rel]
+		to := filepath.Join(CacheDir, rel)
+		if err := os.MkdirAll(filepath.Dir(to), fs.ExtractDirPerms); err != nil {
+			return err
+		}
+		if err := extractFile(src.fsys, src.path, to, SystemCacheDir, rel); err != nil {
+			return err
+		}
+	}
+	updateCP()
+	return nil
+}
+
+// ManifestFile returns the path of the file within the CacheDir in
+// which WatchAndExtract and Reextract record the SHA-256 digest of
+// every file extracted from root (one "digest  path" line each, like
+// sha256sum). Each root has its own so that several (see ExtractAll)
+// can share the same CacheDir.
+func ManifestFile(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	name := ".extracted." + hex.EncodeToString(sum[:8]) + ".sha256"
+	return filepath.Join(CacheDir, name)
+}
+
+// embedSums returns the hex SHA-256 digest of every file under root
+// within fsys keyed by path relative to root.
+func embedSums(fsys embed.FS, root string) (map[string]string, error) {
+	sums := map[string]string{}
+	err := _fs.WalkDir(fsys, root,
+		func(path string, d _fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			buf, err := fsys.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			sum := sha256.Sum256(buf)
+			rel := strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+			sums[rel] = hex.EncodeToString(sum[:])
+			return nil
+		})
+	return sums, err
+}
+
+// readManifest returns the digests recorded in the manifest for root
+// (see ManifestFile), if any, keyed by relative path.
+func readManifest(root string) map[string]string {
+	sums := map[string]string{}
+	buf, err := os.ReadFile(ManifestFile(root))
+	if err != nil {
+		return sums
+	}
+	for _, line := range strings.Split(string(buf), "\n") {
+		sum, rel, found := strings.Cut(line, "  ")
+		if found {
+			sums[rel] = sum
+		}
+	}
+	return sums
+}
+
+// writeManifest writes the digests to the manifest for root (see
+// ManifestFile) sorted by path.
+func writeManifest(root string, sums map[string]string) error {
+	rels := make([]string, 0, len(sums))
+	for rel := range sums {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	var buf strings.Builder
+	for _, rel := range rels {
+		buf.WriteString(sums[rel] + "  " + rel + "\n")
+	}
+	return os.WriteFile(ManifestFile(root), []byte(buf.String()), fs.ExtractFilePerms)
+}
+
+// WatchAndExtract is the same as Extract but also records the digest of
+// every file extracted from root in its manifest (see ManifestFile) so
+// that Reextract can later extract only what has changed.
+func WatchAndExtract(fsys embed.FS, root string) error {
+	if err := Extract(fsys, root); err != nil {
+		return err
+	}
+	sums, err := embedSums(fsys, root)
+	if err != nil {
+		return err
+	}
+	return writeManifest(root, sums)
+}
+
+// Reextract compares the embedded files under root with the manifest
+// for root (see WatchAndExtract and ManifestFile) and the files in the
+// CacheDir, extracts only those that are new, changed, or missing from
+// the cache, removes those recorded in the manifest that are no longer
+// embedded (never those from other roots), updates the manifest, and
+// returns the cached paths (sorted) of everything changed or removed.
+// This allows embedded classes regenerated during development to be
+// reloaded without extracting everything again. Without a manifest
+// every file is extracted.
+func Reextract(fsys embed.FS, root string) ([]string, error) {
+	if err := checkRoot(fsys, root); err != nil {
+		return nil, err
+	}
+	sums, err := embedSums(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	old := readManifest(root)
+	var changed []string
+	for rel, sum := range sums {
+		to := filepath.Join(CacheDir, rel)
+		if old[rel] == sum && Cached(rel) != "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(to), fs.ExtractDirPerms); err != nil {
+			return nil, err
+		}
+		err := extractFile(fsys, path.Join(root, rel), to, SystemCacheDir, rel)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, to)
+	}
+	for rel := range old {
+		if _, has := sums[rel]; has {
+			continue
+		}
+		to := filepath.Join(CacheDir, rel)
+		if err := os.Remove(to); err != nil && !errors.Is(err, _fs.ErrNotExist) {
+			return nil, err
+		}
+		changed = append(changed, to)
+	}
+	sort.Strings(changed)
+	if err := writeManifest(root, sums); err != nil {
+		return nil, err
+	}
+	updateCP()
+	return changed, nil
+}
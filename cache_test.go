@@ -0,0 +1,316 @@
+package java_test
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rwxrob/fs/file"
+	"github.com/rwxrob/java"
+)
+
+func ExampleCachedJarsClasspath() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	os.MkdirAll("testdata/tmpcache/lib", 0700)
+	os.WriteFile("testdata/tmpcache/app.jar", nil, 0600)
+	os.WriteFile("testdata/tmpcache/lib/dep.jar", nil, 0600)
+	os.WriteFile("testdata/tmpcache/Hello.class", nil, 0600)
+
+	cp, err := java.CachedJarsClasspath()
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(cp)
+
+	// Output:
+	// testdata/tmpcache/app.jar:testdata/tmpcache/lib/dep.jar
+}
+
+func ExampleCachedAll() {
+
+	java.CacheDir = "testdata/tmpcache"
+	java.SystemCacheDir = "testdata/tmpsys"
+	defer func() { java.SystemCacheDir = "" }()
+	defer os.RemoveAll("testdata/tmpcache")
+	defer os.RemoveAll("testdata/tmpsys")
+	os.MkdirAll("testdata/tmpcache/v2", 0700)
+	os.MkdirAll("testdata/tmpsys", 0700)
+	os.WriteFile("testdata/tmpcache/app.jar", nil, 0600)
+	os.WriteFile("testdata/tmpcache/v2/app.jar", nil, 0600)
+	os.WriteFile("testdata/tmpsys/app.jar", nil, 0600)
+
+	fmt.Println(java.CachedAll("app.jar"))
+	fmt.Println(java.CachedAll("v2/app.jar"))
+	fmt.Println(java.CachedAll("../app.jar"))
+
+	// Output:
+	// [testdata/tmpcache/app.jar testdata/tmpsys/app.jar testdata/tmpcache/v2/app.jar]
+	// [testdata/tmpcache/v2/app.jar]
+	// []
+}
+
+func ExampleCachedErr() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	if err := java.Extract(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(java.CachedErr("hello.java"))
+	_, err := java.CachedErr("missing.jar")
+	fmt.Println(err)
+	_, err = java.CachedErr("../../java.go")
+	fmt.Println(err)
+	fmt.Printf("%q\n", java.Cached("../../java.go"))
+
+	// Output:
+	// testdata/tmpcache/hello.java <nil>
+	// missing.jar: file does not exist
+	// ../../java.go: path is outside of cache
+	// ""
+}
+
+func ExampleEnsureExtracted() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+
+	if err := java.EnsureExtracted(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(java.Cached("hello.java"))
+
+	os.Remove("testdata/tmpcache/hello.java")
+	if err := java.EnsureExtracted(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%q\n", java.Cached("hello.java"))
+
+	// Output:
+	// testdata/tmpcache/hello.java
+	// ""
+}
+
+func ExampleCacheKey() {
+
+	a, err := java.CacheKey(javafiles, "testdata/javafiles")
+	if err != nil {
+		fmt.Println(err)
+	}
+	b, _ := java.CacheKey(javafiles, "testdata/javafiles")
+
+	fmt.Println(len(a), a == b)
+
+	// Output:
+	// 64 true
+}
+
+func ExampleSystemCacheDir() {
+
+	java.CacheDir = "testdata/tmpcache"
+	java.SystemCacheDir = "testdata/javafiles"
+	defer func() { java.SystemCacheDir = "" }()
+	defer os.RemoveAll("testdata/tmpcache")
+
+	if err := java.Extract(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(file.Exists("testdata/tmpcache/hello.java"))
+	fmt.Println(java.Cached("hello.java"))
+
+	// Output:
+	// false
+	// testdata/javafiles/hello.java
+}
+
+func ExampleSystemCacheDir_classpath() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	os.Setenv("CLASSPATH", "lib/some.jar")
+
+	java.Extract(javafiles, "testdata/javafiles")
+	fmt.Println(os.Getenv("CLASSPATH"))
+
+	java.SystemCacheDir = "testdata/javafiles"
+	defer func() { java.SystemCacheDir = "" }()
+	java.Extract(javafiles, "testdata/javafiles")
+	fmt.Println(os.Getenv("CLASSPATH"))
+
+	// Output:
+	// testdata/tmpcache:lib/some.jar
+	// testdata/tmpcache:testdata/javafiles:lib/some.jar
+}
+
+func ExampleCacheCurrent() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+
+	fmt.Println(java.CacheCurrent(javafiles, "testdata/javafiles"))
+
+	if err := java.Extract(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(java.CacheCurrent(javafiles, "testdata/javafiles"))
+
+	os.WriteFile("testdata/tmpcache/hello.java", []byte("changed"), 0600)
+	fmt.Println(java.CacheCurrent(javafiles, "testdata/javafiles"))
+
+	// Output:
+	// false <nil>
+	// true <nil>
+	// false <nil>
+}
+
+func ExampleSetCacheDirRel() {
+
+	defer func() { java.CacheDir = "testdata/tmpcache" }()
+	wd, _ := os.Getwd()
+
+	java.SetCacheDirRel("testdata", "tmpcache")
+	fmt.Println(filepath.IsAbs(java.CacheDir))
+	fmt.Println(java.CacheDir == filepath.Join(wd, "testdata", "tmpcache"))
+
+	// Output:
+	// true
+	// true
+}
+
+func ExampleTouchCache() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	if err := java.Extract(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+
+	t := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := java.TouchCache(t); err != nil {
+		fmt.Println(err)
+	}
+	info, _ := os.Stat("testdata/tmpcache/hello.java")
+	fmt.Println(info.ModTime().UTC())
+
+	// Output:
+	// 2022-01-01 00:00:00 +0000 UTC
+}
+
+func ExampleWithTempCache() {
+
+	java.CacheDir = "testdata/tmpcache"
+	cp := os.Getenv("CLASSPATH")
+
+	var tmp string
+	err := java.WithTempCache(javafiles, "testdata/javafiles",
+		func(dir string) error {
+			tmp = dir
+			_, err := os.Stat(filepath.Join(dir, "HelloWorld.class"))
+			return err
+		})
+	fmt.Println(err)
+
+	_, err = os.Stat(tmp)
+	fmt.Println(os.IsNotExist(err))
+	fmt.Println(java.CacheDir, os.Getenv("CLASSPATH") == cp)
+
+	// Output:
+	// <nil>
+	// true
+	// testdata/tmpcache true
+}
+
+//go:embed testdata/conflict
+var conflict embed.FS
+
+func ExampleExtractAll() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+
+	err := java.ExtractAll(
+		java.EmbedRoot{javafiles, "testdata/javafiles"},
+		java.EmbedRoot{badpkg, "testdata/badpkg"},
+	)
+	fmt.Println(err)
+	fmt.Println(file.Exists("testdata/tmpcache/hello.java"))
+	fmt.Println(file.Exists("testdata/tmpcache/foo/HelloWorld.class"))
+
+	err = java.ExtractAll(
+		java.EmbedRoot{javafiles, "testdata/javafiles"},
+		java.EmbedRoot{conflict, "testdata/conflict"},
+	)
+	fmt.Println(err)
+
+	// Output:
+	// <nil>
+	// true
+	// true
+	// conflicting embedded files: [hello.java]
+}
+
+func ExampleReextract() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+
+	if err := java.WatchAndExtract(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(java.Reextract(javafiles, "testdata/javafiles"))
+
+	os.Remove("testdata/tmpcache/hello.java")
+	f, _ := os.OpenFile(java.ManifestFile("testdata/javafiles"), os.O_APPEND|os.O_WRONLY, 0)
+	fmt.Fprintln(f, "0000  gone.jar")
+	f.Close()
+
+	fmt.Println(java.Reextract(javafiles, "testdata/javafiles"))
+	fmt.Println(file.Exists("testdata/tmpcache/hello.java"))
+
+	// Output:
+	// [] <nil>
+	// [testdata/tmpcache/gone.jar testdata/tmpcache/hello.java] <nil>
+	// true
+}
+
+//go:embed testdata/roots
+var roots embed.FS
+
+func ExampleReextract_roots() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+
+	fmt.Println(java.WatchAndExtract(roots, "testdata/roots/a"))
+	fmt.Println(java.WatchAndExtract(roots, "testdata/roots/b"))
+	fmt.Println(java.Reextract(roots, "testdata/roots/a"))
+	fmt.Println(java.Reextract(roots, "testdata/roots/b"))
+	fmt.Println(file.Exists("testdata/tmpcache/sub/a.txt"))
+	fmt.Println(file.Exists("testdata/tmpcache/sub/b.txt"))
+
+	// Output:
+	// <nil>
+	// <nil>
+	// [] <nil>
+	// [] <nil>
+	// true
+	// true
+}
+
+func ExampleListEmbedded() {
+
+	fmt.Println(java.ListEmbedded(badpkg, "testdata/badpkg"))
+	_, err := java.ListEmbedded(badpkg, "testdata/nope")
+	fmt.Println(err)
+
+	// Output:
+	// [HelloWorld.class foo/HelloWorld.class] <nil>
+	// embed root "testdata/nope" not found
+}
@@ -0,0 +1,36 @@
+package java
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// CreateCDS creates the Class Data Sharing archive (see
+// Cmd.SharedArchive) at path archive from the list of classes in the
+// classlist file (resolved against the cache) using java -Xshare:dump
+// with the CacheDir and CLASSPATH as the classpath (see ExecCP). This is
+// the second of two steps. The classlist is created first by running
+// the program once with the -XX:DumpLoadedClassList=<classlist> option.
+// The same java version and classpath must be used when running with
+// the archive. Any output from java is included in the error.
+func CreateCDS(classlist, archive string) error {
+	if path := Cached(classlist); path != "" {
+		classlist = path
+	}
+	args := hookArgv([]string{
+		"java", "-Xshare:dump",
+		"-XX:SharedClassListFile=" + classlist,
+		"-XX:SharedArchiveFile=" + archive,
+		"-cp", classpath(),
+	})
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+	out, err := exec.Command(path, args[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %v", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
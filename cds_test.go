@@ -0,0 +1,26 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleCreateCDS() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/echofail")
+	os.Setenv("PATH", bin)
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	os.Setenv("CLASSPATH", "")
+
+	// the fake java fails with its arguments as output
+	err := java.CreateCDS("app.classlist", "app.jsa")
+	fmt.Println(strings.ReplaceAll(err.Error(), java.CacheDir, "CACHE"))
+
+	// Output:
+	// exit status 1: -Xshare:dump -XX:SharedClassListFile=app.classlist -XX:SharedArchiveFile=app.jsa -cp CACHE
+}
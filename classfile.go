@@ -0,0 +1,286 @@
+package java
+
+import (
+	"archive/zip"
+	"bufio"
+	"embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotClass is returned when a file is not a Java class file.
+var ErrNotClass = errors.New("not a class file (bad magic)")
+
+// classHeader contains the parts of a class file that are of interest.
+type classHeader struct {
+	Major int
+	Minor int
+	Name  string // internal form (foo/bar/Baz)
+}
+
+// readClass reads the version and (internal) name of the class from the
+// beginning of the class file read from r skipping over the constant
+// pool as required.
+func readClass(r io.Reader) (*classHeader, error) {
+	br := bufio.NewReader(r)
+	var head struct {
+		Magic        uint32
+		Minor, Major uint16
+		Count        uint16
+	}
+	if err := binary.Read(br, binary.BigEndian, &head); err != nil {
+		return nil, err
+	}
+	if head.Magic != 0xCAFEBABE {
+		return nil, ErrNotClass
+	}
+	h := &classHeader{Major: int(head.Major), Minor: int(head.Minor)}
+
+	utf8 := map[uint16]string{}
+	classes := map[uint16]uint16{}
+	for i := uint16(1); i < head.Count; i++ {
+		tag, err := br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		var skip int
+		switch tag {
+		case 1: // Utf8
+			var n uint16
+			if err := binary.Read(br, binary.BigEndian, &n); err != nil {
+				return nil, err
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(br, buf); err != nil {
+				return nil, err
+			}
+			utf8[i] = string(buf)
+		case 7: // Class
+			var idx uint16
+			if err := binary.Read(br, binary.BigEndian, &idx); err != nil {
+				return nil, err
+			}
+			classes[i] = idx
+		case 8, 16, 19, 20: // String, MethodType, Module, Package
+			skip = 2
+		case 15: // MethodHandle
+			skip = 3
+		case 3, 4, 9, 10, 11, 12, 17, 18: // Integer, Float, refs, ...
+			skip = 4
+		case 5, 6: // Long, Double (take two slots)
+			skip = 8
+			i++
+		default:
+			return nil, fmt.Errorf("unknown constant pool tag %v", tag)
+		}
+		if _, err := br.Discard(skip); err != nil {
+			return nil, err
+		}
+	}
+
+	var access, this uint16
+	if err := binary.Read(br, binary.BigEndian, &access); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(br, binary.BigEndian, &this); err != nil {
+		return nil, err
+	}
+	h.Name = utf8[classes[this]]
+	return h, nil
+}
+
+// readClassFile is the same as readClass but opens the file at path.
+func readClassFile(path string) (*classHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h, err := readClass(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return h, nil
+}
+
+// ClassVersion returns the major version of the class file at path
+// (resolved against the cache). Subtract 44 to get the Java release
+// (61 is Java 17) which is the minimum needed to load the class.
+func ClassVersion(path string) (int, error) {
+	if cached := Cached(path); cached != "" {
+		path = cached
+	}
+	h, err := readClassFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return h.Major, nil
+}
+
+// ClassName returns the fully qualified name (foo.bar.Baz) declared by
+// the class file at path (resolved against the cache).
+func ClassName(path string) (string, error) {
+	if cached := Cached(path); cached != "" {
+		path = cached
+	}
+	h, err := readClassFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(h.Name, "/", "."), nil
+}
+
+// Locate returns the location of the class file for the fully qualified
+// class name by searching the CacheDir, SystemCacheDir, and then each
+// CLASSPATH entry (directories and jars) in order. Classes found within
+// a jar are returned as the jar path followed by ! and the entry
+// (lib/foo.jar!foo/Bar.class). An error wrapping fs.ErrNotExist is
+// returned if not found.
+func Locate(class string) (string, error) {
+	file := Class2Path(class)
+	for _, entry := range filepath.SplitList(classpath()) {
+		if strings.HasSuffix(entry, ".jar") {
+			if jarHas(entry, filepath.ToSlash(file)) {
+				return entry + "!" + filepath.ToSlash(file), nil
+			}
+			continue
+		}
+		path := filepath.Join(entry, file)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%v: %w", class, os.ErrNotExist)
+}
+
+// jarHas returns true if the jar at path contains the named entry.
+func jarHas(path, name string) bool {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Explain returns a human readable explanation of where (and whether)
+// the fully qualified class can be found to help diagnose
+// ClassNotFoundException and NoClassDefFoundError failures. It checks
+// the cache and CLASSPATH (see Locate), whether the class declared
+// within a found file matches its location, the class file version,
+// and whether a class with the same simple name exists elsewhere in the
+// cache (usually meaning the package was left off).
+func Explain(class string) string {
+	class = normalizeName(class)
+	file := Class2Path(class)
+	loc, err := Locate(class)
+	if err != nil {
+		msg := fmt.Sprintf("%v not found in any of %q",
+			file, filepath.SplitList(classpath()))
+		simple := class[strings.LastIndex(class, ".")+1:]
+		for _, it := range findClasses(simple) {
+			name, err := ClassName(it)
+			if err == nil && name != class {
+				msg += fmt.Sprintf("; but found %v which declares %v"+
+					" (use the fully qualified name)", it, name)
+			}
+		}
+		return msg
+	}
+	if strings.Contains(loc, "!") {
+		return fmt.Sprintf("found %v", loc)
+	}
+	h, err := readClassFile(loc)
+	if err != nil {
+		return fmt.Sprintf("found %v but cannot read it: %v", loc, err)
+	}
+	declared := strings.ReplaceAll(h.Name, "/", ".")
+	if declared != class {
+		return fmt.Sprintf("found %v but it declares %v (package %q)"+
+			" which does not match its location",
+			loc, declared, packageOf(declared))
+	}
+	return fmt.Sprintf("found %v (class file version %v, Java %v or later)",
+		loc, h.Major, h.Major-44)
+}
+
+// packageOf returns the package portion of a fully qualified class name.
+func packageOf(class string) string {
+	if i := strings.LastIndex(class, "."); i >= 0 {
+		return class[:i]
+	}
+	return ""
+}
+
+// findClasses returns the paths to all class files within the CacheDir
+// (and SystemCacheDir) with the given simple name.
+func findClasses(simple string) []string {
+	var found []string
+	for _, dir := range []string{CacheDir, SystemCacheDir} {
+		if dir == "" {
+			continue
+		}
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && d.Name() == simple+".class" {
+				found = append(found, path)
+			}
+			return nil
+		})
+	}
+	return found
+}
+
+// VerifyPackages returns an error for every class file under root
+// within fsys whose declared package does not match the directory it is
+// in (relative to root) or that cannot be read as a class file. Java
+// cannot load such classes so this is best called from tests (or
+// before Extract) to catch the problem early rather than with
+// a NoClassDefFoundError at run time.
+func VerifyPackages(fsys embed.FS, root string) []error {
+	var errs []error
+	err := fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(file, ".class") {
+			return nil
+		}
+		f, err := fsys.Open(file)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		h, err := readClass(f)
+		f.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", file, err))
+			return nil
+		}
+		dir := strings.TrimPrefix(strings.TrimPrefix(path.Dir(file), root), "/")
+		pkg := path.Dir(h.Name)
+		if pkg == "." {
+			pkg = ""
+		}
+		if pkg != dir {
+			errs = append(errs, fmt.Errorf("%v: declares package %q but is in %q",
+				file, strings.ReplaceAll(pkg, "/", "."), dir))
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
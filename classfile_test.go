@@ -0,0 +1,67 @@
+package java_test
+
+import (
+	"embed"
+	"fmt"
+	"os"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleClassVersion() {
+
+	fmt.Println(java.ClassVersion("testdata/javafiles/HelloWorld.class"))
+	fmt.Println(java.ClassName("testdata/javafiles/HelloWorld.class"))
+
+	// Output:
+	// 62 <nil>
+	// HelloWorld <nil>
+}
+
+func ExampleLocate() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	os.Setenv("CLASSPATH", "testdata/files.jar:testdata/javafiles")
+
+	fmt.Println(java.Locate("HelloWorld"))
+
+	// Output:
+	// testdata/files.jar!HelloWorld.class <nil>
+}
+
+func ExampleExplain() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	os.Setenv("CLASSPATH", "testdata/javafiles")
+
+	buf, _ := os.ReadFile("testdata/javafiles/HelloWorld.class")
+	os.MkdirAll("testdata/tmpcache/foo", 0700)
+	os.WriteFile("testdata/tmpcache/foo/HelloWorld.class", buf, 0600)
+
+	fmt.Println(java.Explain("HelloWorld"))
+	fmt.Println(java.Explain("foo.HelloWorld"))
+	fmt.Println(java.Explain("Greeting"))
+
+	// Output:
+	// found testdata/javafiles/HelloWorld.class (class file version 62, Java 18 or later)
+	// found testdata/tmpcache/foo/HelloWorld.class but it declares HelloWorld (package "") which does not match its location
+	// Greeting.class not found in any of ["testdata/tmpcache" "testdata/javafiles"]
+}
+
+//go:embed testdata/badpkg
+var badpkg embed.FS
+
+func ExampleVerifyPackages() {
+
+	fmt.Println(java.VerifyPackages(javafiles, "testdata/javafiles"))
+	for _, err := range java.VerifyPackages(badpkg, "testdata/badpkg") {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// []
+	// testdata/badpkg/foo/HelloWorld.class: declares package "" but is in "foo"
+}
@@ -0,0 +1,140 @@
+package java
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// classpath returns a classpath beginning with CacheDir (and
+// SystemCacheDir, if set) followed by the entries passed and then those of the CLASSPATH environment variable
+// (if any) with empty and duplicate entries removed.
+func classpath(entries ...string) string {
+	all := []string{CacheDir, SystemCacheDir}
+	all = append(all, entries...)
+	all = append(all, SplitClasspath(os.Getenv("CLASSPATH"))...)
+	var cp []string
+	seen := map[string]bool{}
+	for _, it := range all {
+		if it == "" || seen[it] {
+			continue
+		}
+		seen[it] = true
+		cp = append(cp, it)
+	}
+	return JoinClasspath(cp)
+}
+
+// SplitClasspath splits cp into its entries on the os.PathListSeparator
+// (see filepath.SplitList) cleaning each (see filepath.Clean) and
+// dropping any that are empty (including those from leading, doubled, or
+// trailing separators).
+func SplitClasspath(cp string) []string {
+	var entries []string
+	for _, it := range filepath.SplitList(cp) {
+		if it = strings.TrimSpace(it); it != "" {
+			entries = append(entries, filepath.Clean(it))
+		}
+	}
+	return entries
+}
+
+// JoinClasspath joins the cleaned non-empty entries with the
+// os.PathListSeparator. It is the inverse of SplitClasspath.
+func JoinClasspath(entries []string) string {
+	var cp []string
+	for _, it := range entries {
+		if it = strings.TrimSpace(it); it != "" {
+			cp = append(cp, filepath.Clean(it))
+		}
+	}
+	return strings.Join(cp, string(os.PathListSeparator))
+}
+
+// cpArgv is the same as argv but adds a -cp option with the classpath
+// of CacheDir, extra, and CLASSPATH (in that order).
+func cpArgv(extra []string, cmd ...string) ([]string, error) {
+	c := ParseCmd(cmd...)
+	c.Options = append([]string{"-cp", classpath(extra...)}, c.Options...)
+	return c.argv()
+}
+
+// ExecCP is the same as Exec but passes the classpath explicitly as a
+// -cp option (rather than changing the CLASSPATH environment variable)
+// made up of the CacheDir, followed by the extraCP entries, followed
+// by any entries from CLASSPATH. The CacheDir therefore always has
+// priority. Note that java ignores -cp when running with -jar.
+func ExecCP(extraCP []string, cmd ...string) error {
+	args, err := cpArgv(extraCP, cmd...)
+	if err != nil {
+		return err
+	}
+	return execArgv(args...)
+}
+
+// OutCP is the same as ExecCP but returns the standard output as
+// a string and logs any errors (see Out).
+func OutCP(extraCP []string, cmd ...string) string {
+	args, err := cpArgv(extraCP, cmd...)
+	if err != nil {
+		Logger.Println(err)
+		return ""
+	}
+	return out(args...)
+}
+
+// ExecWithClasspath is the same as ExecCP but uses exactly the
+// classpath cp (ignoring CLASSPATH) except that the CacheDir is always
+// added to the beginning of it (unless already there) so that cached
+// files keep their priority.
+func ExecWithClasspath(cp string, cmd ...string) error {
+	switch entries := filepath.SplitList(cp); {
+	case len(entries) == 0:
+		cp = CacheDir
+	case entries[0] != CacheDir:
+		cp = CacheDir + string(os.PathListSeparator) + cp
+	}
+	c := ParseCmd(cmd...)
+	c.Options = append([]string{"-cp", cp}, c.Options...)
+	args, err := c.argv()
+	if err != nil {
+		return err
+	}
+	return execArgv(args...)
+}
+
+// ExecManifest is the same as ExecWithClasspath but reads the classpath
+// entries from manifest (such as a classpath file generated by a build
+// system) which may have one entry per line or many entries on a line
+// joined with the os.PathListSeparator (or ; on any system). Blank lines
+// are ignored. An error is returned without running anything if any
+// entry (other than a wildcard ending in *) does not exist.
+func ExecManifest(manifest io.Reader, cmd ...string) error {
+	seps := string(os.PathListSeparator) + ";"
+	var entries, missing []string
+	s := bufio.NewScanner(manifest)
+	for s.Scan() {
+		for _, it := range strings.FieldsFunc(s.Text(),
+			func(r rune) bool { return strings.ContainsRune(seps, r) }) {
+			it = strings.TrimSpace(it)
+			if it == "" {
+				continue
+			}
+			if _, err := os.Stat(it); err != nil && !strings.HasSuffix(it, "*") {
+				missing = append(missing, it)
+			}
+			entries = append(entries, it)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("classpath entries not found: %v", missing)
+	}
+	return ExecWithClasspath(
+		strings.Join(entries, string(os.PathListSeparator)), cmd...)
+}
@@ -0,0 +1,71 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleOutCP() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	os.Setenv("CLASSPATH", "")
+
+	fmt.Println(java.OutCP([]string{"testdata/javafiles"}, "HelloWorld"))
+
+	// Output:
+	// Hello, World!
+}
+
+func ExampleExecManifest() {
+
+	java.CacheDir = "testdata/tmpcache"
+	manifest := strings.NewReader("testdata/javafiles:testdata/files.jar\nlib/*\nnope.jar\n")
+
+	err := java.ExecManifest(manifest, "HelloWorld")
+	fmt.Println(err)
+
+	// Output:
+	// classpath entries not found: [nope.jar]
+}
+
+func ExampleExecWithClasspath() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/fakejava")
+	os.Setenv("PATH", bin)
+	stderr := os.Stderr
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stderr = stderr }()
+	defer func() { java.ArgvHook = nil }()
+
+	java.ArgvHook = func(argv []string) []string {
+		fmt.Println(argv[1:])
+		return argv
+	}
+
+	java.ExecWithClasspath("lib/a.jar:lib/b.jar", "Main")
+	java.ExecWithClasspath("testdata/tmpcache:lib/a.jar", "Main")
+	java.ExecWithClasspath("", "-Dx=y", "Main")
+
+	// Output:
+	// [-cp testdata/tmpcache:lib/a.jar:lib/b.jar Main]
+	// [-cp testdata/tmpcache:lib/a.jar Main]
+	// [-cp testdata/tmpcache -Dx=y Main]
+}
+
+func ExampleSplitClasspath() {
+
+	entries := java.SplitClasspath(":lib/foo.jar::./classes/:lib/*:")
+	fmt.Printf("%q\n", entries)
+	fmt.Println(java.JoinClasspath(append(entries, "", "other/../x.jar")))
+
+	// Output:
+	// ["lib/foo.jar" "classes" "lib/*"]
+	// lib/foo.jar:classes:lib/*:x.jar
+}
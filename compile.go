@@ -0,0 +1,69 @@
+package java
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Compile extracts (see Extract) the embedded file system starting at
+// root into the CacheDir and then compiles every ".java" file found
+// there with javac writing the ".class" files into the CacheDir as
+// well. Returns ErrNoJDK if javac is not found or an error containing
+// the javac diagnostics if compilation fails.
+func Compile(fsys embed.FS, root string) error {
+	return CompileRelease(fsys, root, 0)
+}
+
+// CompileRelease is the same as Compile but passes --release to javac
+// (when greater than zero) so that the cached ".class" files are
+// compatible with that release no matter which JDK is on the host.
+func CompileRelease(fsys embed.FS, root string, release int) error {
+	javac := sibling("javac")
+	if javac == "" {
+		return ErrNoJDK
+	}
+
+	if err := checkRoot(fsys, root); err != nil {
+		return err
+	}
+
+	var sources []string
+	err := fs.WalkDir(fsys, root,
+		func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, ".java") {
+				sources = append(sources,
+					filepath.Join(CacheDir, strings.TrimPrefix(path, root)))
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	if err := Extract(fsys, root); err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	args := []string{"-d", CacheDir}
+	if release > 0 {
+		args = append(args, "--release", strconv.Itoa(release))
+	}
+	args = append(args, sources...)
+
+	out, err := exec.Command(javac, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("javac: %w\n%s", err, out)
+	}
+	return nil
+}
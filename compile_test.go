@@ -0,0 +1,35 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleCompileRelease() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/echojavac")
+	os.Setenv("PATH", bin)
+
+	defer func(dir string) { java.CacheDir = dir }(java.CacheDir)
+	java.CacheDir, _ = os.MkdirTemp("", "gojavacache")
+	defer os.RemoveAll(java.CacheDir)
+
+	// the fake javac fails with its arguments as diagnostics
+	err := java.Compile(javafiles, "testdata/javafiles")
+	fmt.Println(strings.ReplaceAll(err.Error(), java.CacheDir, "CACHE"))
+
+	err = java.CompileRelease(javafiles, "testdata/javafiles", 11)
+	fmt.Println(strings.ReplaceAll(err.Error(), java.CacheDir, "CACHE"))
+
+	// Output:
+	// javac: exit status 1
+	// -d CACHE CACHE/fooprop.java CACHE/greeting.java CACHE/hello.java
+	//
+	// javac: exit status 1
+	// -d CACHE --release 11 CACHE/fooprop.java CACHE/greeting.java CACHE/hello.java
+}
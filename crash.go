@@ -0,0 +1,65 @@
+package java
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CrashDir is the directory searched first by LastCrashLog. It should
+// match the directory of any -XX:ErrorFile option. When empty the
+// current working directory (the JVM default) is used.
+var CrashDir string
+
+// LastCrashLog returns the contents of the most recently modified JVM
+// crash log (hs_err_pid*.log) found in the CrashDir (or current
+// working directory) or the os.TempDir (where the JVM writes it when the
+// first is not writable). Call it after a run fails with a non-zero
+// exit to surface the details of a JVM crash. An error wrapping
+// fs.ErrNotExist is returned if there are none.
+func LastCrashLog() (string, error) {
+	dir := CrashDir
+	if dir == "" {
+		dir = "."
+	}
+	return lastCrashLog("hs_err_pid*.log", dir, os.TempDir())
+}
+
+// LastCrashLog is the same as the LastCrashLog function but looks for
+// logs matching the ErrorFile (with %p matching any process ID) when
+// set, and in the CrashDir (or current directory) and os.TempDir
+// otherwise.
+func (c *Cmd) LastCrashLog() (string, error) {
+	if c.ErrorFile == "" {
+		return LastCrashLog()
+	}
+	pattern := strings.ReplaceAll(filepath.Base(c.ErrorFile), "%p", "*")
+	return lastCrashLog(pattern, filepath.Dir(c.ErrorFile), os.TempDir())
+}
+
+// lastCrashLog returns the contents of the most recently modified file
+// matching the glob pattern within any of the dirs.
+func lastCrashLog(pattern string, dirs ...string) (string, error) {
+	var last string
+	var mod time.Time
+	for _, d := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(d, pattern))
+		for _, it := range matches {
+			info, err := os.Stat(it)
+			if err != nil {
+				continue
+			}
+			if last == "" || info.ModTime().After(mod) {
+				last, mod = it, info.ModTime()
+			}
+		}
+	}
+	if last == "" {
+		return "", fmt.Errorf("no %v found: %w", pattern, fs.ErrNotExist)
+	}
+	buf, err := os.ReadFile(last)
+	return string(buf), err
+}
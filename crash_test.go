@@ -0,0 +1,43 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleLastCrashLog() {
+
+	java.CrashDir = "testdata/crash"
+	defer func() { java.CrashDir = "" }()
+	now := time.Now()
+	os.Chtimes("testdata/crash/hs_err_pid100.log", now, now.Add(-time.Hour))
+	os.Chtimes("testdata/crash/hs_err_pid200.log", now, now)
+
+	log, err := java.LastCrashLog()
+	fmt.Print(log, err)
+
+	// Output:
+	// # A fatal error has been detected (new)
+	// <nil>
+}
+
+func ExampleCmd_LastCrashLog() {
+
+	c := java.ParseCmd("-XX:ErrorFile=testdata/crash/hs_err_pid%p.log", "Main")
+	fmt.Println(c.ErrorFile)
+
+	now := time.Now()
+	os.Chtimes("testdata/crash/hs_err_pid100.log", now, now)
+	os.Chtimes("testdata/crash/hs_err_pid200.log", now, now.Add(-time.Hour))
+
+	log, err := c.LastCrashLog()
+	fmt.Print(log, err)
+
+	// Output:
+	// testdata/crash/hs_err_pid%p.log
+	// # A fatal error has been detected (old)
+	// <nil>
+}
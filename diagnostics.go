@@ -0,0 +1,47 @@
+package java
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Diagnostics returns a report (one "name: value" line each) of
+// everything that affects how java is found and run: the java
+// executable (and where it really is), its version, architecture, and
+// charset, whether javac and jshell are next to it, the CacheDir and
+// SystemCacheDir, the CLASSPATH, and the classpath the JVM actually
+// resolves (see EffectiveClasspath). Anything that cannot be determined
+// (for example, when java is not installed) is reported with its error
+// rather than failing so that it is always safe to call and to include
+// in a support request.
+func Diagnostics() string {
+	var b strings.Builder
+	line := func(name string, val any, err error) {
+		if err != nil {
+			val = "error: " + err.Error()
+		}
+		b.WriteString(strings.TrimRight(fmt.Sprintf("%v: %v", name, val), " ") + "\n")
+	}
+	path, err := exec.LookPath("java")
+	line("java", path, err)
+	if err == nil {
+		bin, err := javaBin()
+		line("java bin", bin, err)
+	}
+	ver, err := Version()
+	line("version", ver, err)
+	arch, err := JVMArch()
+	line("arch", arch+" (host "+HostArch()+")", err)
+	charset, err := Charset()
+	line("charset", charset, err)
+	line("javac", HasJavac(), nil)
+	line("jshell", sibling("jshell") != "", nil)
+	line("cache", CacheDir, nil)
+	line("system cache", SystemCacheDir, nil)
+	line("CLASSPATH", os.Getenv("CLASSPATH"), nil)
+	cp, err := EffectiveClasspath()
+	line("effective classpath", cp, err)
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleDiagnostics() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	os.Setenv("PATH", "")
+	os.Setenv("CLASSPATH", "")
+
+	for _, line := range strings.Split(strings.TrimSpace(java.Diagnostics()), "\n") {
+		fmt.Println(line)
+	}
+	fmt.Printf("%q\n", os.Getenv("CLASSPATH"))
+
+	// Output:
+	// java: error: exec: "java": executable file not found in $PATH
+	// version: error: exec: "java": executable file not found in $PATH
+	// arch: error: exec: "java": executable file not found in $PATH
+	// charset: error: exec: "java": executable file not found in $PATH
+	// javac: false
+	// jshell: false
+	// cache: testdata/tmpcache
+	// system cache:
+	// CLASSPATH:
+	// effective classpath: error: exec: "java": executable file not found in $PATH
+	// ""
+}
+
+func ExampleDiagnostics_classpath() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	bin, _ := filepath.Abs("testdata/fakejava")
+	os.Setenv("PATH", bin)
+	os.Setenv("CLASSPATH", "lib/foo.jar")
+
+	for _, line := range strings.Split(java.Diagnostics(), "\n") {
+		if strings.Contains(line, "classpath") || strings.HasPrefix(line, "CLASSPATH") {
+			fmt.Println(line)
+		}
+	}
+	fmt.Println(os.Getenv("CLASSPATH"))
+
+	// Output:
+	// CLASSPATH: lib/foo.jar
+	// effective classpath: testdata/tmpcache:lib/foo.jar
+	// lib/foo.jar
+}
@@ -0,0 +1,109 @@
+package java
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/rwxrob/java/internal"
+)
+
+// maxStderr is the most standard error (the end of it) kept for an
+// ExecError.
+const maxStderr = 64 << 10
+
+// ExecError is returned by the run functions (Exec, ExecOut, ExecScan,
+// Pipe, Run, Cmd.Run, Cmd.Output, Process.Wait, and others) when the java process exits with
+// a non-zero code. It contains everything needed to diagnose the
+// failure: what was run (beginning with the full path to java), what
+// java complained about (the end of the standard error even when it
+// was also passed through to os.Stderr), and the exit code. Use
+// errors.As to get it and errors.As (or Unwrap) again for the
+// underlying *exec.ExitError.
+type ExecError struct {
+	Argv   []string
+	Stderr string
+	Code   int
+	Err    error
+}
+
+// Error returns the underlying error followed by the last line of
+// Stderr (if any).
+func (e *ExecError) Error() string {
+	lines := strings.Split(strings.TrimSpace(e.Stderr), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if last == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Err, last)
+}
+
+// Unwrap returns the underlying error (usually an *exec.ExitError).
+func (e *ExecError) Unwrap() error { return e.Err }
+
+// tailBuffer keeps only the last max bytes written to it.
+type tailBuffer struct {
+	buf []byte
+	max int
+}
+
+func (t *tailBuffer) Write(b []byte) (int, error) {
+	t.buf = append(t.buf, b...)
+	if len(t.buf) > t.max {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.max:]...)
+	}
+	return len(b), nil
+}
+
+func (t *tailBuffer) String() string { return string(t.buf) }
+
+// run is the same as cmd.Run but also keeps the end of the standard
+// error (see captureStderr) so that an *ExecError can be returned if
+// java exits with a non-zero code.
+func run(cmd *exec.Cmd) error {
+	tail := captureStderr(cmd)
+	err := cmd.Run()
+	return execError(cmd, tail.String(), err)
+}
+
+// captureStderr adds a tailBuffer to the standard error of cmd (which
+// must not have been started yet) and returns it. Standard error merged
+// into standard output (see Cmd.MergeStderr) is left alone and not
+// captured.
+func captureStderr(cmd *exec.Cmd) *tailBuffer {
+	tail := &tailBuffer{max: maxStderr}
+	switch {
+	case cmd.Stderr == nil:
+		cmd.Stderr = tail
+	case cmd.Stderr != cmd.Stdout:
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, tail)
+	}
+	return tail
+}
+
+// execArgv is the same as internal.Exec but returns an *ExecError if
+// java exits with a non-zero code (see run).
+func execArgv(args ...string) error {
+	cmd, err := internal.Command(args...)
+	if err != nil {
+		return err
+	}
+	return run(cmd)
+}
+
+// execError returns an *ExecError for cmd if err is an *exec.ExitError
+// (with the captured stderr) or err unchanged.
+func execError(cmd *exec.Cmd, stderr string, err error) error {
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) {
+		return err
+	}
+	return &ExecError{
+		Argv:   cmd.Args,
+		Stderr: stderr,
+		Code:   exit.ExitCode(),
+		Err:    err,
+	}
+}
@@ -0,0 +1,46 @@
+package java_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleExecError() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/failjava")
+	os.Setenv("PATH", bin)
+	stderr := os.Stderr
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stderr = stderr }()
+
+	err := java.Exec("Main", "arg")
+	var e *java.ExecError
+	if errors.As(err, &e) {
+		fmt.Println(filepath.Base(e.Argv[0]), e.Argv[1:], e.Code)
+		fmt.Print(e.Stderr)
+	}
+	fmt.Println(err)
+
+	err = java.ExecScan(nil, nil, "Main")
+	fmt.Println(errors.As(err, &e), err)
+
+	_, err = java.Pipe([]string{"Main"}, []string{"Other"})
+	fmt.Println(errors.As(err, &e), err)
+
+	p, _ := java.ExecAsync("Main")
+	err = p.Wait()
+	fmt.Println(errors.As(err, &e), err)
+
+	// Output:
+	// java [Main arg] 3
+	// Exception in thread "main" java.lang.IllegalStateException: boom
+	// exit status 3: Exception in thread "main" java.lang.IllegalStateException: boom
+	// true exit status 3: Exception in thread "main" java.lang.IllegalStateException: boom
+	// true stage 1: exit status 3: Exception in thread "main" java.lang.IllegalStateException: boom
+	// true exit status 3: Exception in thread "main" java.lang.IllegalStateException: boom
+}
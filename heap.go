@@ -0,0 +1,24 @@
+package java
+
+import "strconv"
+
+// SuggestHeap returns suggested values for Cmd.MaxHeap (-Xmx) and
+// Cmd.MinHeap (-Xms) based on the physical memory of the host: half of
+// it (capped at 31g so that compressed object pointers remain enabled)
+// for the maximum and a quarter of that for the minimum. If the memory
+// cannot be detected conservative values of 512m and 64m are returned.
+func SuggestHeap() (max string, min string) {
+	total, err := hostMemory()
+	if err != nil || total == 0 {
+		return "512m", "64m"
+	}
+	mb := total / 2 >> 20
+	if mb > 31<<10 {
+		mb = 31 << 10
+	}
+	if mb < 64 {
+		mb = 64
+	}
+	return strconv.FormatUint(mb, 10) + "m",
+		strconv.FormatUint(mb/4, 10) + "m"
+}
@@ -0,0 +1,21 @@
+package java
+
+import (
+	"encoding/binary"
+	"fmt"
+	"syscall"
+)
+
+// hostMemory returns the total physical memory in bytes from the
+// hw.memsize sysctl.
+func hostMemory() (uint64, error) {
+	v, err := syscall.Sysctl("hw.memsize")
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 {
+		return 0, fmt.Errorf("empty hw.memsize")
+	}
+	buf := append([]byte(v), make([]byte, 8)...) // Sysctl drops trailing zeros
+	return binary.LittleEndian.Uint64(buf[:8]), nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package java
+
+import "errors"
+
+// hostMemory is not supported on this system (see SuggestHeap).
+func hostMemory() (uint64, error) {
+	return 0, errors.New("host memory detection not supported")
+}
@@ -0,0 +1,29 @@
+package java_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleSuggestHeap() {
+
+	max, min := java.SuggestHeap()
+	c := java.ParseCmd("Main")
+	c.MaxHeap, c.MinHeap = max, min
+
+	fmt.Println(strings.HasSuffix(max, "m"), strings.HasSuffix(min, "m"))
+	fmt.Println(len(c.Argv()))
+
+	c = java.ParseCmd("-Xmx1g", "-Dx=y", "-Xms512m", "Main")
+	fmt.Println(c.MaxHeap, c.MinHeap)
+	c.MaxHeap, c.MinHeap = "4g", "1g"
+	fmt.Println(c.Argv())
+
+	// Output:
+	// true true
+	// 4
+	// 1g 512m
+	// [java -Xmx4g -Dx=y -Xms1g Main]
+}
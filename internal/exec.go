@@ -4,8 +4,8 @@
 package internal
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 )
@@ -17,35 +17,34 @@ import (
 // insufficient and the UNIX-specific SysExec is preferred. For example,
 // when handing over control to a terminal editor such as Vim.
 func Exec(args ...string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("missing name of executable")
-	}
-	path, err := exec.LookPath(args[0])
+	cmd, err := Command(args...)
 	if err != nil {
 		return err
 	}
-	cmd := exec.Command(path, args[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stdin = os.Stdin
-	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-// Out returns the standard output of the executed command as
-// a string. Errors are logged but not returned.
-func Out(args ...string) string {
+// Command checks for existence of first argument as an executable on
+// the system and returns an *exec.Cmd for it with stdin, stdout, and
+// stderr connected to those of the calling program (which may then be
+// changed before running it).
+func Command(args ...string) (*exec.Cmd, error) {
+	return CommandContext(context.Background(), args...)
+}
+
+// CommandContext is the same as Command but the returned *exec.Cmd is
+// killed when the context is done (see exec.CommandContext).
+func CommandContext(ctx context.Context, args ...string) (*exec.Cmd, error) {
 	if len(args) == 0 {
-		log.Println("missing name of executable")
-		return ""
+		return nil, fmt.Errorf("missing name of executable")
 	}
 	path, err := exec.LookPath(args[0])
 	if err != nil {
-		log.Println(err)
-		return ""
-	}
-	out, err := exec.Command(path, args[1:]...).Output()
-	if err != nil {
-		log.Println(err)
+		return nil, err
 	}
-	return string(out)
+	cmd := exec.CommandContext(ctx, path, args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	return cmd, nil
 }
@@ -0,0 +1,29 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// SysExec checks for existence of first argument as an executable on
+// the system and then replaces the current process with it (execve)
+// passing the current environment. It only returns if there is an
+// error. This is preferred to Exec when handing over full control of
+// the terminal, for example, to a terminal editor such as Vim.
+func SysExec(args ...string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing name of executable")
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(path, args, os.Environ())
+}
@@ -0,0 +1,10 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+// Copyright 2022 Robert S. Muhlestein.
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+// SysExec falls back to Exec on systems without execve (Windows) and
+// therefore returns (like Exec) once the command has completed.
+func SysExec(args ...string) error { return Exec(args...) }
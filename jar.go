@@ -0,0 +1,182 @@
+package java
+
+import (
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ManifestPath is the location of the manifest within every jar file.
+const ManifestPath = "META-INF/MANIFEST.MF"
+
+// jarManifest returns the main section attributes of the manifest of the
+// jar file at path. Continuation lines (beginning with a single space)
+// are joined to the previous line as required by the JAR specification.
+func jarManifest(path string) (map[string]string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	f, err := r.Open(ManifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	defer f.Close()
+
+	attrs := map[string]string{}
+	var last string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if line == "" {
+			break // end of main section
+		}
+		if line[0] == ' ' && last != "" {
+			attrs[last] += line[1:]
+			continue
+		}
+		key, val, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		last = strings.TrimSpace(key)
+		attrs[last] = strings.TrimPrefix(val, " ")
+	}
+	return attrs, s.Err()
+}
+
+// JarClassPath returns the relative entries of the Class-Path header
+// from the manifest of the jar file at path (usually one that is
+// cached). These must be resolvable relative to the jar itself when it
+// is run with -jar and therefore should also be extracted into the
+// CacheDir to avoid NoClassDefFoundError. An empty slice is returned if
+// there is no Class-Path header.
+func JarClassPath(path string) ([]string, error) {
+	attrs, err := jarManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(attrs["Class-Path"]), nil
+}
+
+// JarMainClass returns the Main-Class header from the manifest of the
+// jar file at path or an error if there is none.
+func JarMainClass(path string) (string, error) {
+	attrs, err := jarManifest(path)
+	if err != nil {
+		return "", err
+	}
+	main := strings.TrimSpace(attrs["Main-Class"])
+	if main == "" {
+		return "", fmt.Errorf("%v: no Main-Class in manifest", path)
+	}
+	return main, nil
+}
+
+// JarMultiRelease returns the Java releases (sorted) for which the
+// multi-release jar at path provides versioned classes (under
+// META-INF/versions/N/). Compare the lowest with Version to warn when
+// the host JDK is older than the jar expects. An empty slice is
+// returned if the manifest does not declare Multi-Release: true since
+// the versioned classes are ignored by java in that case.
+func JarMultiRelease(path string) ([]int, error) {
+	attrs, err := jarManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(attrs["Multi-Release"]), "true") {
+		return []int{}, nil
+	}
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	seen := map[int]bool{}
+	releases := []int{}
+	for _, f := range r.File {
+		rest, ok := cutPrefix(f.Name, "META-INF/versions/")
+		if !ok || !strings.HasSuffix(f.Name, ".class") {
+			continue
+		}
+		dir, _, _ := strings.Cut(rest, "/")
+		n, err := strconv.Atoi(dir)
+		if err != nil || seen[n] {
+			continue
+		}
+		seen[n] = true
+		releases = append(releases, n)
+	}
+	sort.Ints(releases)
+	return releases, nil
+}
+
+// ExecJarMain runs the Main-Class (see JarMainClass) of the jar
+// (resolved against the cache) with the jar on the classpath (-cp jar
+// MainClass) rather than with -jar. The classpath begins with the jar
+// followed by its manifest Class-Path entries (relative to the jar),
+// the CacheDir, and then any CLASSPATH entries which -jar would
+// otherwise ignore.
+func ExecJarMain(jar string, args ...string) error {
+	jar, _ = resolve(jar)
+	main, err := JarMainClass(jar)
+	if err != nil {
+		return err
+	}
+	deps, err := JarClassPath(jar)
+	if err != nil {
+		return err
+	}
+	entries := []string{jar}
+	for _, it := range deps {
+		entries = append(entries, filepath.Join(filepath.Dir(jar), it))
+	}
+	cp := strings.Join(entries, string(os.PathListSeparator)) +
+		string(os.PathListSeparator) + classpath()
+	c := &Cmd{Name: main, Options: []string{"-cp", cp}, Args: args}
+	argv, err := c.argv()
+	if err != nil {
+		return err
+	}
+	return execArgv(argv...)
+}
+
+// VerifyJar returns true if the jar at path (resolved against the
+// cache) is validly signed according to jarsigner -verify (using the
+// jarsigner next to java). Unsigned and tampered jars return false with
+// no error. Any error from os.Stat of the jar (such as one not
+// existing) is returned first and ErrNoJDK if jarsigner cannot be
+// found.
+func VerifyJar(path string) (bool, error) {
+	path, _ = resolve(path)
+	if _, err := os.Stat(path); err != nil {
+		return false, err
+	}
+	jarsigner := sibling("jarsigner")
+	if jarsigner == "" {
+		return false, ErrNoJDK
+	}
+	out, err := exec.Command(jarsigner, "-verify", path).CombinedOutput()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "jar verified." {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,102 @@
+package java_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleJarClassPath() {
+
+	cp, err := java.JarClassPath("testdata/deps.jar")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(cp)
+
+	cp, err = java.JarClassPath("testdata/files.jar")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(cp)
+
+	// Output:
+	// [lib/first.jar lib/second.jar lib/third.jar]
+	// []
+}
+
+func ExampleJarMainClass() {
+
+	fmt.Println(java.JarMainClass("testdata/files.jar"))
+
+	// Output:
+	// HelloWorld <nil>
+}
+
+func ExampleJarMultiRelease() {
+
+	fmt.Println(java.JarMultiRelease("testdata/mr.jar"))
+	fmt.Println(java.JarMultiRelease("testdata/files.jar"))
+
+	// Output:
+	// [9 11 17] <nil>
+	// [] <nil>
+}
+
+func ExampleExecJarMain() {
+
+	if err := java.ExecJarMain("testdata/files.jar"); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// Hello, World!
+}
+
+func ExampleExecJarMain_echo() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/runtime/bin")
+	os.Setenv("PATH", bin)
+
+	java.Echo = true
+	java.Logger.SetOutput(os.Stdout)
+	java.Logger.SetFlags(0)
+	defer func() {
+		java.Echo = false
+		java.ArgvHook = nil
+		java.Logger.SetOutput(os.Stderr)
+		java.Logger.SetFlags(log.LstdFlags)
+	}()
+
+	// drops the -cp (which includes the CacheDir)
+	java.ArgvHook = func(argv []string) []string {
+		return append(argv[:1:1], argv[3:]...)
+	}
+	if err := java.ExecJarMain("testdata/files.jar", "some", "arg"); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// java HelloWorld some arg
+	// HelloWorld some arg
+}
+
+func ExampleVerifyJar() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/jarsigner")
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	fmt.Println(java.VerifyJar("testdata/signed.jar"))
+	fmt.Println(java.VerifyJar("testdata/files.jar"))
+	fmt.Println(java.VerifyJar("testdata/missing.jar"))
+
+	// Output:
+	// true <nil>
+	// false <nil>
+	// false stat testdata/missing.jar: no such file or directory
+}
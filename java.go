@@ -1,5 +1,4 @@
 /*
-
 Package java uses Go embed.FS so that packages can be created to
 encapsulate Java JAR, class, and raw source files that have been
 embedded into the package with the default java executable on the host
@@ -11,23 +10,35 @@ and other java-specific environment variables.
 
 Options beginning with dash passed as arguments before the main
 class/jar/java file are preserved. Options must use the equals or colon
-format to avoid confusion with the main identifier. Arguments following
-the class/jar/java argument are passed as expected.  No shell expansion
-is performed.
+format to avoid confusion with the main identifier except for those in
+ValueOptions (-cp foo.jar) which may also have their value as the
+following argument. Arguments following the class/jar/java argument are
+passed as expected.  No shell expansion is performed.
 
 The Exec function maps the output of the java command to the system
 stdin/out/err (which can be redirected to a file by assigning to
 os.Stdin, etc.) while the Out function returns a string with stdout and
-logs stderr (see internal/exec.go).
-
+logs any error (see Logger).
 */
 package java
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	_fs "io/fs"
+	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/rwxrob/fs"
 	"github.com/rwxrob/java/internal"
@@ -35,16 +46,307 @@ import (
 
 // Cmd is a java command line with options preceding the named
 // class/jar/java file. Args come after.
+//
+// The remaining fields are typed conveniences for common options.
+// ParseCmd sets them from the corresponding options (which also remain
+// in Options). When set, each is rendered (see Argv) in the place of
+// the last option of the same kind in Options (so that the original
+// order is kept) or, if there is none, before all Options. Clearing a
+// field (UseG1 = false) only stops it from being rendered. Any options
+// of the same kind that remain in Options are still rendered as they
+// are and must be removed from Options to drop them.
 type Cmd struct {
 	Name    string
 	Options []string
 	Args    []string
+
+	// Executable is the java executable to run (default "java" from
+	// the system path). See JavaFromManager.
+	Executable string `json:",omitempty"`
+
+	// Locale (ex: en_US, en-US, fr) sets the user.language and (if
+	// given) user.country system properties taking the place of any
+	// -Duser.language and -Duser.country Options (which ParseCmd
+	// leaves without setting Locale).
+	Locale string `json:",omitempty"`
+
+	// Timezone (ex: UTC, America/New_York) sets the user.timezone
+	// system property.
+	Timezone string `json:",omitempty"`
+
+	// MaxHeap and MinHeap (ex: 512m, 4g) set the maximum (-Xmx) and
+	// initial (-Xms) heap size. See SuggestHeap.
+	MaxHeap string `json:",omitempty"`
+	MinHeap string `json:",omitempty"`
+
+	// MaxRAMPercentage (when non-zero) sets -XX:MaxRAMPercentage which
+	// limits the maximum heap to a percentage of the memory available
+	// to the JVM. Since JDK 10 the JVM is container-aware and uses the
+	// cgroup memory limit rather than host memory making this the
+	// preferred way to size the heap when running in containers.
+	MaxRAMPercentage float64 `json:",omitempty"`
+
+	// GCThreads (when non-zero) sets -XX:ParallelGCThreads, the number
+	// of threads used for the parallel phases of garbage collection.
+	GCThreads int `json:",omitempty"`
+
+	// UseG1 and UseZGC select the G1 (-XX:+UseG1GC) or Z
+	// (-XX:+UseZGC) garbage collector. G1 balances throughput and pause
+	// times (and is the default for most systems) while ZGC keeps pauses
+	// very short at some cost to throughput. Only one collector may be
+	// selected (see Validate).
+	UseG1  bool `json:",omitempty"`
+	UseZGC bool `json:",omitempty"`
+
+	// ExitOnOOM sets -XX:+ExitOnOutOfMemoryError so that the JVM exits
+	// (with a non-zero status that can be detected) on the first
+	// OutOfMemoryError rather than continuing in an unknown state.
+	ExitOnOOM bool `json:",omitempty"`
+
+	// FlightRecord starts a Java Flight Recorder recording when the JVM
+	// starts (-XX:StartFlightRecording) which is written to the
+	// FlightRecordFile (ex: app.jfr) when the JVM exits. Setting
+	// FlightRecordFile alone also starts a recording. The deprecated
+	// (since JDK 13) -XX:+FlightRecorder is not needed and is not
+	// rendered.
+	FlightRecord     bool   `json:",omitempty"`
+	FlightRecordFile string `json:",omitempty"`
+
+	// ErrorFile (ex: /var/log/app/hs_err_%p.log) sets -XX:ErrorFile
+	// which is where the JVM writes its fatal error (crash) log (%p is
+	// replaced with the process ID). See Cmd.LastCrashLog.
+	ErrorFile string `json:",omitempty"`
+
+	// HeapDumpPath (ex: /var/dumps) sets -XX:HeapDumpPath along with
+	// -XX:+HeapDumpOnOutOfMemoryError so that a heap dump is written
+	// there (as a file or into a directory) on the first
+	// OutOfMemoryError.
+	HeapDumpPath string `json:",omitempty"`
+
+	// Agents (ex: profiler.jar, agent.jar=opt1,opt2) are each rendered
+	// as a -javaagent option (in order). Relative agent jar paths are
+	// resolved against the cache (see Cached) when cached.
+	Agents []string `json:",omitempty"`
+
+	// LibraryPath entries (ex: native/linux-amd64) are joined into the
+	// java.library.path system property where JNI native libraries
+	// (.so, .dylib, .dll) are loaded from. Relative entries are resolved
+	// against the cache (see Cached) when cached so that embedded native
+	// libraries are found once extracted.
+	LibraryPath []string `json:",omitempty"`
+
+	// SharedArchive (ex: app.jsa) is a Class Data Sharing archive (see
+	// CreateCDS) rendered as -XX:SharedArchiveFile along with -Xshare:on
+	// (unless another -Xshare option is in Options) to speed up JVM
+	// startup. A relative path is resolved against the cache (see
+	// Cached) when cached.
+	SharedArchive string `json:",omitempty"`
+
+	// Splash (ex: splash.png) is the image shown by -splash while a GUI
+	// application starts. A relative path is resolved against the cache
+	// (see Cached) when cached so that an embedded image is found once
+	// extracted.
+	Splash string `json:",omitempty"`
+
+	// FileEncoding (ex: UTF-8) sets the file.encoding system property
+	// which determines the default charset used to read and write
+	// text. Since JDK 18 the default is always UTF-8 (JEP 400) so
+	// setting UTF-8 only matters for older JDKs where the default
+	// depends on the platform. Setting COMPAT on JDK 18+ restores the
+	// old platform-dependent behavior.
+	FileEncoding string `json:",omitempty"`
+
+	// BaseDir sets the basedir system property that many Java tools use
+	// to resolve their resources and configuration. Set it to the
+	// CacheDir for programs that expect their resources next to the
+	// extracted jar or classes.
+	BaseDir string `json:",omitempty"`
+
+	// Properties are rendered as -Dkey=value system property options
+	// (sorted by key). A java.security.manager property (other than
+	// "disallow") is dropped with a warning logged when the java is JDK
+	// 24 or later (see Version) since the Security Manager has been
+	// removed and the JVM would otherwise refuse to start. The same is
+	// done for any -Djava.security.manager option in Options (where
+	// ParseCmd leaves all -D options). Checking the version runs java
+	// -version (once for each Executable) from Argv, String, and
+	// everything that executes the Cmd.
+	Properties map[string]string `json:",omitempty"`
+
+	// MergeStderr routes the standard error of the java process to the
+	// same destination as its standard output (see Run and Output).
+	MergeStderr bool `json:",omitempty"`
+
+	// Module (ex: com.foo/com.foo.Main) is the main module (and
+	// optionally main class) to run with -m instead of Name. When set
+	// the CacheDir (see ModulePath) is also added to the end of any
+	// --module-path (or -p) Options or as a new --module-path option.
+	Module string `json:",omitempty"`
+
+	// AddOpens and AddExports (ex: java.base/java.lang=ALL-UNNAMED) are
+	// each rendered as a repeated --add-opens or --add-exports option
+	// (in order) allowing code on the classpath (ALL-UNNAMED) or other
+	// modules to reach into packages of modules that do not open or
+	// export them, which many older libraries require on JDK 16+.
+	AddOpens   []string `json:",omitempty"`
+	AddExports []string `json:",omitempty"`
+
+	// LogPrefix (ex: "[auth] ") is written at the beginning of every
+	// line of the standard output and error of the java process (see
+	// Run and Start) so that the interleaved output of several running
+	// at once can be told apart (even when they share the same Stdout
+	// and Stderr writer). Output returned as a string (see Output) is
+	// never prefixed.
+	LogPrefix string `json:",omitempty"`
+
+	// Umask (ex: 0o027) is the file mode creation mask set for the java
+	// process only (see Run) restricting the permissions of any files
+	// it creates. It is applied by running java through the POSIX shell
+	// (sh) which means ProgramName is ignored when it is set. Zero
+	// leaves the umask of the current process (see WithUmask) in
+	// effect. Only Unix-like systems have a umask.
+	Umask int `json:",omitempty"`
+
+	// ProgramName (when set) is passed as argv[0] to the java process
+	// instead of the path to the executable (see Run). This only works
+	// on Unix-like systems. Note that most Java programs cannot see
+	// argv[0] at all and that the launchers on some (non-Linux) systems
+	// use it to locate the runtime so it should be used with care.
+	ProgramName string `json:",omitempty"`
+
+	// AutoColor (ex: --no-color) is added to the end of the Args
+	// whenever the standard output is not going to a terminal (such as
+	// when Stdout is set, when captured by Output, or when os.Stdout is
+	// not a terminal) for those Java programs that do not detect this
+	// for themselves.
+	AutoColor string `json:",omitempty"`
+
+	// Echo logs the command line (see String) to the Logger before
+	// running it (see the package Echo variable).
+	Echo bool `json:",omitempty"`
+
+	// Timeout (when non-zero) is the longest the java process may run
+	// (see Run and Output) before it is killed and ErrTimeout returned.
+	Timeout time.Duration `json:",omitempty"`
+
+	// MaxOutputBytes (when non-zero) overrides the package
+	// MaxOutputBytes for this Cmd.
+	MaxOutputBytes int `json:",omitempty"`
+
+	// Stdout and Stderr (when not nil) receive the standard output and
+	// error of the java process (see Run). Note that reusing the same
+	// buffers for multiple runs accumulates the output of all of them
+	// unless they are Reset between runs.
+	Stdout *bytes.Buffer `json:"-"`
+	Stderr *bytes.Buffer `json:"-"`
+
+	// ExtraFiles are additional open files inherited by the java
+	// process as file descriptors 3, 4, and so on (see
+	// exec.Cmd.ExtraFiles) allowing a pre-bound listener socket or
+	// pipe to be handed to it. Not supported on Windows.
+	ExtraFiles []*os.File `json:"-"`
+
+	// Unrecognized contains any options that ParseCmd could not
+	// confidently classify. They remain in Options as well.
+	Unrecognized []string `json:",omitempty"`
 }
 
+// IsTerminal returns true if os.Stdout is a terminal (character device)
+// rather than a file or pipe.
+func IsTerminal() bool { return isTerminal(os.Stdout) }
+
+// isTerminal returns true if w is a file that is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// stdout returns the Stdout or os.Stdout if it is nil.
+func (c *Cmd) stdout() io.Writer {
+	if c.Stdout != nil {
+		return c.Stdout
+	}
+	return os.Stdout
+}
+
+// cmdJSON prevents recursion in MarshalJSON and UnmarshalJSON.
+type cmdJSON Cmd
+
+// MarshalJSON encodes the Cmd as JSON including the Name, Options,
+// Args, and any typed fields that are set. Stdout, Stderr, and
+// ExtraFiles are never included.
+func (c *Cmd) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*cmdJSON)(c))
+}
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON into the Cmd
+// replacing all of its fields (including any previously set Stdout,
+// Stderr, and ExtraFiles).
+func (c *Cmd) UnmarshalJSON(buf []byte) error {
+	n := new(cmdJSON)
+	if err := json.Unmarshal(buf, n); err != nil {
+		return err
+	}
+	*c = Cmd(*n)
+	return nil
+}
+
+// Logger is used for all logging from this package including the
+// output from Trace. It may be replaced or redirected at any time.
+var Logger = log.New(os.Stderr, "", log.LstdFlags)
+
+// Echo enables logging (to Logger) of the command line (see
+// Cmd.String) of every command before it is run, like set -x in the
+// shell. Also see Cmd.Echo and Trace.
+var Echo bool
+
+// Trace enables logging (to Logger) of every resolution decision made
+// before running java: the main class/jar/java chosen, whether it came
+// from the cache, the final argv, and the effective CLASSPATH.
+var Trace bool
+
+// DefaultMain (when set) is the main class (or jar, java file, and so
+// on) used whenever a Cmd has neither a Name nor a Module (usually
+// because only options were passed to Exec). If it is also empty
+// ErrNoMain is returned instead of running java.
+var DefaultMain string
+
+// ErrNoMain is returned when there is no main class, jar, java file, or
+// module to run (see DefaultMain).
+var ErrNoMain = errors.New("no main class, jar, or module given")
+
+// ArgvHook (when set) is called with the fully resolved argv (beginning
+// with the java executable) just before every java command is run
+// (including by Exec, Out, Cmd.Run, and all the others) and the argv
+// it returns is run instead. It must return at least the executable.
+// Use it for cross-cutting concerns such as always adding an
+// organization-wide -D property. Echo and Trace log the returned argv.
+var ArgvHook func(argv []string) []string
+
 // CacheDir is set to os.UserCacheDir() plus "gojavacache" by default at
-// init time.
+// init time. Setting it to a relative path is discouraged since the
+// location then depends on the working directory at the time of each
+// call (see SetCacheDirRel).
 var CacheDir string
 
+// SetCacheDirRel sets the CacheDir to the absolute path of rel joined to
+// the anchor directory so that the cache location remains stable no
+// matter what the working directory of the process is later.
+func SetCacheDirRel(anchor, rel string) {
+	dir := filepath.Join(anchor, rel)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	CacheDir = dir
+}
+
 func init() {
 	dir, err := os.UserCacheDir()
 	if err == nil {
@@ -52,38 +354,266 @@ func init() {
 	}
 }
 
-// careful not to call more than once since will duplicate
+// SystemCacheDir is an optional, usually read-only, cache directory
+// (for example, one baked into a container image layer) that is
+// consulted by Cached whenever a file is not found in the CacheDir. It
+// follows CacheDir in the CLASSPATH and Extract does not write any file
+// into CacheDir that already exists identically in SystemCacheDir.
+var SystemCacheDir string
+
+// updateCP puts CacheDir followed by SystemCacheDir (if set) at the
+// beginning of CLASSPATH (moving them if already there) keeping all
+// other entries in order after them.
 func updateCP() {
-	if os.Getenv("CLASSPATH") == "" {
-		os.Setenv("CLASSPATH", CacheDir)
-		return
+	var lead []string
+	for _, dir := range []string{CacheDir, SystemCacheDir} {
+		if dir != "" {
+			lead = append(lead, dir)
+		}
+	}
+	cp := append([]string{}, lead...)
+	for _, it := range filepath.SplitList(os.Getenv("CLASSPATH")) {
+		var found bool
+		for _, dir := range lead {
+			if it == dir {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cp = append(cp, it)
+		}
 	}
-	os.Setenv("CLASSPATH",
-		CacheDir+string(os.PathListSeparator)+os.Getenv("CLASSPATH"))
+	os.Setenv("CLASSPATH", strings.Join(cp, string(os.PathListSeparator)))
+}
+
+// checkRoot returns an error if root does not exist within the embedded
+// file system (usually from a mistyped embed path).
+func checkRoot(fsys embed.FS, root string) error {
+	if _, err := _fs.Stat(fsys, root); err != nil {
+		return fmt.Errorf("embed root %q not found", root)
+	}
+	return nil
 }
 
 // Extract explicitly extracts all of an embedded file system into the
 // CacheDir starting from the root path passed. Files in the CacheDir
 // always have priority over anything else on the system since CacheDir
-// is added to the beginning of the CLASSPATH.
+// is added to the beginning of the CLASSPATH. Files that already exist
+// identically in the SystemCacheDir are skipped.
 func Extract(fsys embed.FS, root string) error {
-	os.MkdirAll(CacheDir, fs.ExtractDirPerms)
-	if err := fs.ExtractEmbed(fsys, root, CacheDir); err != nil {
+	return ExtractProgress(fsys, root, nil)
+}
+
+// ExtractProgress is the same as Extract but calls cb (unless nil)
+// after each file with the path to which it was extracted, the number
+// of files done so far, and the total number of files (so that
+// a progress bar can be shown). Files skipped because they exist in the
+// SystemCacheDir are also counted.
+func ExtractProgress(fsys embed.FS, root string, cb func(path string, n, total int)) error {
+	if err := extractTree(fsys, root, CacheDir, SystemCacheDir, cb); err != nil {
 		return err
 	}
 	updateCP()
 	return nil
 }
 
+// extractTree extracts everything under root within fsys into dir
+// (skipping files that exist identically in sys, unless empty) calling
+// cb (see ExtractProgress) after each. The CLASSPATH is not changed.
+func extractTree(fsys embed.FS, root, dir, sys string, cb func(path string, n, total int)) error {
+	if err := checkRoot(fsys, root); err != nil {
+		return err
+	}
+	var n, total int
+	if cb != nil {
+		_fs.WalkDir(fsys, root,
+			func(path string, d _fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					total++
+				}
+				return err
+			})
+	}
+	os.MkdirAll(dir, fs.ExtractDirPerms)
+	return _fs.WalkDir(fsys, root,
+		func(path string, d _fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel := strings.TrimPrefix(path, root)
+			to := filepath.Join(dir, rel)
+			if d.IsDir() {
+				return os.MkdirAll(to, fs.ExtractDirPerms)
+			}
+			if err := extractFile(fsys, path, to, sys, rel); err != nil {
+				return err
+			}
+			if cb != nil {
+				n++
+				cb(to, n, total)
+			}
+			return nil
+		})
+}
+
+// extractFile writes the embedded file at path to the file at to
+// unless it already exists identically at rel within sys (usually the
+// SystemCacheDir).
+func extractFile(fsys embed.FS, path, to, sys, rel string) error {
+	buf, err := fsys.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if sys != "" {
+		cur, err := os.ReadFile(filepath.Join(sys, rel))
+		if err == nil && bytes.Equal(cur, buf) {
+			return nil
+		}
+	}
+	return os.WriteFile(to, buf, fs.ExtractFilePerms)
+}
+
+// ExtractFile extracts the single file at embeddedPath within the
+// embedded file system into the CacheDir (using only its base name)
+// and returns the full path to the cached file. This is more efficient
+// than Extract when only one artifact (usually a jar) is needed.
+func ExtractFile(fsys embed.FS, embeddedPath string) (string, error) {
+	buf, err := fsys.ReadFile(embeddedPath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(CacheDir, fs.ExtractDirPerms); err != nil {
+		return "", err
+	}
+	path := filepath.Join(CacheDir, filepath.Base(embeddedPath))
+	if err := os.WriteFile(path, buf, fs.ExtractFilePerms); err != nil {
+		return "", err
+	}
+	updateCP()
+	return path, nil
+}
+
 // Cached returns the full path the extracted cache location of the file
 // indicated by it. Note that extraction does not happen automatically
-// and must be explicitly done by calling Extract.
+// and must be explicitly done by calling Extract. An empty string is
+// returned if the file is not cached or if it would be outside of the
+// CacheDir (see CachedErr). When more than one match exists (see
+// CachedAll) the first, highest-priority one is returned: the exact
+// path within CacheDir and then within SystemCacheDir.
 func Cached(file string) string {
-	path := filepath.Join(CacheDir, file)
-	if fs.Exists(path) {
-		return path
+	path, _ := CachedErr(file)
+	return path
+}
+
+// ErrOutsideCache is returned when a path would escape the CacheDir.
+var ErrOutsideCache = errors.New("path is outside of cache")
+
+// CachedErr is the same as Cached but returns an error wrapping
+// fs.ErrNotExist if the file is not in the cache or ErrOutsideCache
+// if the cleaned path would escape the CacheDir (../../etc/passwd).
+// Use this whenever file comes from untrusted input. The
+// SystemCacheDir (if set) is checked when not found in CacheDir.
+func CachedErr(file string) (string, error) {
+	path, err := cachedIn(CacheDir, file)
+	if errors.Is(err, _fs.ErrNotExist) && SystemCacheDir != "" {
+		return cachedIn(SystemCacheDir, file)
+	}
+	return path, err
+}
+
+// CachedAll returns every cached match for file (highest-priority
+// first) so that callers can decide between them: the exact path
+// within CacheDir and SystemCacheDir (as with Cached) followed by, for
+// a bare file name (hello.jar), any file with that name in any
+// subdirectory of either. An empty slice is returned if there are none
+// or if file would escape the cache.
+func CachedAll(file string) []string {
+	dirs := []string{CacheDir, SystemCacheDir}
+	seen := map[string]bool{}
+	var all []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			all = append(all, path)
+		}
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if path, err := cachedIn(dir, file); err == nil {
+			add(path)
+		}
+	}
+	if strings.ContainsAny(file, `/\`) {
+		return all
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		filepath.WalkDir(dir, func(path string, d _fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && d.Name() == file {
+				add(path)
+			}
+			return nil
+		})
+	}
+	return all
+}
+
+func cachedIn(dir, file string) (string, error) {
+	path := filepath.Join(dir, file)
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." ||
+		strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%v: %w", file, ErrOutsideCache)
 	}
-	return ""
+	if !fs.Exists(path) {
+		return "", fmt.Errorf("%v: %w", file, _fs.ErrNotExist)
+	}
+	return path, nil
+}
+
+// ValueOptions are the java options known to take their value as the
+// following (space separated) argument. ParseCmd keeps the value with
+// the option rather than mistaking it for the Name.
+var ValueOptions = []string{
+	"-cp", "-classpath", "--class-path",
+	"-p", "--module-path", "--upgrade-module-path",
+	"--add-modules", "--limit-modules", "--enable-native-access",
+	"--add-opens", "--add-exports", "--add-reads", "--patch-module",
+}
+
+// TerminalOptions are the java options that only print information
+// (and exit) rather than running anything. A Cmd with any of them in
+// its Options needs no Name (or DefaultMain) and is run as is (java
+// -version).
+var TerminalOptions = []string{
+	"-version", "--version", "-help", "--help", "-h", "-?",
+	"-X", "--help-extra", "--list-modules",
+}
+
+// terminal returns true if any of the Options are TerminalOptions.
+func (c *Cmd) terminal() bool {
+	for _, it := range c.Options {
+		for _, t := range TerminalOptions {
+			if it == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isValueOption(opt string) bool {
+	for _, it := range ValueOptions {
+		if it == opt {
+			return true
+		}
+	}
+	return false
 }
 
 // ParseCmd parses a typical java command line with options beginning
@@ -91,26 +621,110 @@ func Cached(file string) string {
 // is considered the Name, or main class/java/jar file (see Cmd). The
 // remaining arguments are stored as arguments to the class/java/jar
 // itself.
+//
+// Options listed in ValueOptions may also have their value as the
+// following argument (-cp foo.jar). Options corresponding to the typed
+// fields of Cmd (-XX:MaxRAMPercentage=50) are also assigned to those
+// fields (the last one winning) but remain in Options so that they are
+// rendered in their original place (see Cmd). Any option token that
+// cannot be confidently classified (a lone dash, or a ValueOptions option
+// missing its value) is kept in Options but also added to
+// Unrecognized so that callers can warn about it.
+//
+// The -m, --module, and --module= options (and their values) are assigned to
+// Module which, like Name, ends the options.
+//
+// The original order of the Options (including those also assigned to
+// typed fields, which matters for -XX flags that override one another)
+// and Args is always preserved, both in Options and when rendered by
+// Argv.
+//
+// Like java itself, any @file argument before the Name is replaced by
+// the whitespace separated arguments read from that file (ignoring
+// lines beginning with #). Use @@ to pass a literal argument beginning
+// with @. Argument files may refer to others but each is expanded at
+// most once (preventing infinite recursion). Any that cannot be
+// expanded are kept and added to Unrecognized.
 func ParseCmd(cmd ...string) *Cmd {
 	c := new(Cmd)
+	cmd = append([]string(nil), cmd...)
+	seen := map[string]bool{}
 
-	for _, it := range cmd {
+	for i := 0; i < len(cmd); i++ {
+		it := cmd[i]
+		if !c.hasMain() && strings.HasPrefix(it, "@") {
+			if strings.HasPrefix(it, "@@") {
+				cmd[i] = it[1:]
+				it = cmd[i]
+			} else {
+				args, err := readArgfile(it[1:])
+				if err == nil && !seen[it[1:]] {
+					seen[it[1:]] = true
+					cmd = append(cmd[:i], append(args, cmd[i+1:]...)...)
+					i--
+					continue
+				}
+				c.Options = append(c.Options, it)
+				c.Unrecognized = append(c.Unrecognized, it)
+				continue
+			}
+		}
 		if !strings.HasPrefix(it, "-") {
-			if c.Name == "" {
+			if !c.hasMain() {
 				c.Name = it
 				continue
 			}
 		}
-		if c.Name == "" {
-			c.Options = append(c.Options, it)
-		} else {
+		if c.hasMain() {
 			c.Args = append(c.Args, it)
+			continue
+		}
+		if (it == "-m" || it == "--module") && i+1 < len(cmd) {
+			i++
+			c.Module = cmd[i]
+			continue
+		}
+		if strings.HasPrefix(it, "--module=") {
+			c.Module = it[9:]
+			continue
+		}
+		c.parseOption(it)
+		c.Options = append(c.Options, it)
+		switch {
+		case it == "-":
+			c.Unrecognized = append(c.Unrecognized, it)
+		case isValueOption(it):
+			if i+1 >= len(cmd) || strings.HasPrefix(cmd[i+1], "-") {
+				c.Unrecognized = append(c.Unrecognized, it)
+				continue
+			}
+			i++
+			c.Options = append(c.Options, cmd[i])
+			c.parseOption(it + "=" + cmd[i])
 		}
 	}
 
 	return c
 }
 
+// readArgfile returns the whitespace separated arguments from the java
+// argument file at path ignoring any lines beginning with #.
+func readArgfile(path string) ([]string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, line := range strings.Split(string(buf), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		args = append(args, strings.Fields(line)...)
+	}
+	return args, nil
+}
+
 // Class2Path translates a simple string into a class name adding the
 // ".class" suffix if needed and replacing the dots (.) with the
 // os.PathSeparator.
@@ -122,6 +736,187 @@ func Class2Path(cl string) string {
 	return cl + ".class"
 }
 
+// Path2Class is the inverse of Class2Path translating the path of
+// a class file (relative to a classpath entry) into a fully qualified
+// class name by removing the ".class" suffix and replacing the path
+// separators with dots (.).
+func Path2Class(path string) string {
+	path = strings.TrimSuffix(filepath.ToSlash(path), ".class")
+	return strings.ReplaceAll(path, "/", ".")
+}
+
+// Clone returns a deep copy of the Cmd so that appending to any of the
+// slices (Options, Args, and so on) of the copy never mutates the
+// original. The Stdout and Stderr buffers and the ExtraFiles themselves
+// are shared.
+func (c *Cmd) Clone() *Cmd {
+	n := *c
+	n.Options = append([]string(nil), c.Options...)
+	n.Args = append([]string(nil), c.Args...)
+	n.Unrecognized = append([]string(nil), c.Unrecognized...)
+	n.ExtraFiles = append([]*os.File(nil), c.ExtraFiles...)
+	n.Agents = append([]string(nil), c.Agents...)
+	n.LibraryPath = append([]string(nil), c.LibraryPath...)
+	n.AddOpens = append([]string(nil), c.AddOpens...)
+	n.AddExports = append([]string(nil), c.AddExports...)
+	if c.Properties != nil {
+		n.Properties = make(map[string]string, len(c.Properties))
+		for k, v := range c.Properties {
+			n.Properties[k] = v
+		}
+	}
+	return &n
+}
+
+// hasMain returns true if either the Name or Module has been set.
+func (c *Cmd) hasMain() bool { return c.Name != "" || c.Module != "" }
+
+// main returns the main class/jar/java argument to use (the
+// DefaultMain if there is no Name unless it has TerminalOptions, with
+// any package inferred, see InferPackage) and whether it was resolved
+// from the cache.
+func (c *Cmd) main() (string, bool) {
+	if c.Name == "" {
+		if c.terminal() {
+			return "", false
+		}
+		return resolve(inferPackage(normalizeName(DefaultMain)))
+	}
+	return resolve(inferPackage(normalizeName(c.Name)))
+}
+
+// NormalizeName trims any whitespace from the Name, removes a stray
+// ".class" suffix from a class name (foo.bar.Some.class), and resolves
+// any "jar", "java", or "jsh" file against the cache (see Cached). The
+// same normalization is always done (without changing Name) by Argv.
+func (c *Cmd) NormalizeName() { c.Name, _ = c.main() }
+
+// normalizeName trims name and removes any ".class" suffix from a class
+// name (but not a path).
+func normalizeName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.HasSuffix(name, ".class") && !strings.ContainsAny(name, `/\`) {
+		return strings.TrimSuffix(name, ".class")
+	}
+	return name
+}
+
+// resolve returns the cached path of any "jar", "java", or "jsh" file
+// (see Kind) if cached and whether it came from the cache.
+func resolve(name string) (string, bool) {
+	switch Kind(name) {
+	case "jar", "java", "jsh":
+		if path := Cached(name); path != "" {
+			return path, true
+		}
+	}
+	return name, false
+}
+
+// Kind returns the kind of thing that name refers to based on its suffix
+// and the file system: "jar", "java", "jsh", "jmod", "class" (a ".class"
+// file or a dotted class name with no suffix), or "path" (any other
+// existing file or directory or anything containing a path separator).
+func Kind(name string) string {
+	switch filepath.Ext(name) {
+	case ".jar":
+		return "jar"
+	case ".java":
+		return "java"
+	case ".jsh":
+		return "jsh"
+	case ".jmod":
+		return "jmod"
+	case ".class":
+		return "class"
+	}
+	if strings.ContainsAny(name, `/\`) || fs.Exists(name) {
+		return "path"
+	}
+	return "class"
+}
+
+// Argv returns the full command line (beginning with "java" or the
+// Executable) that would be executed for the Cmd. If the Name is
+// a "jar", "java", or "jsh" file (see Kind) and a cached version exists
+// (see Cached) the cached path is used instead. The AutoColor (if any)
+// is included unless the Stdout is nil and os.Stdout is a terminal.
+func (c *Cmd) Argv() []string { return c.args(c.stdout()) }
+
+// args returns the Argv for standard output written to out.
+func (c *Cmd) args(out io.Writer) []string {
+	main, _ := c.main()
+	args := []string{"java"}
+	if c.Executable != "" {
+		args[0] = c.Executable
+	}
+	args = append(args, c.options()...)
+	if c.Module != "" {
+		args = append(args, "-m", c.Module)
+	} else if main != "" {
+		args = append(args, main)
+	}
+	args = append(args, c.Args...)
+	if c.AutoColor != "" && !isTerminal(out) {
+		args = append(args, c.AutoColor)
+	}
+	return args
+}
+
+// String returns the Argv as a single line with any argument containing
+// spaces or other characters special to the shell single quoted so that
+// it could be pasted into a POSIX shell.
+func (c *Cmd) String() string { return shellJoin(c.Argv()) }
+
+// shellJoin joins args with spaces single quoting any that need it.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, it := range args {
+		if it != "" && !strings.ContainsAny(it, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+			quoted[i] = it
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(it, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// argv parses cmd and returns the resolved argv (see Cmd.argv).
+func argv(cmd ...string) ([]string, error) { return ParseCmd(cmd...).argv() }
+
+// argv returns Argv logging each resolution decision when Trace is
+// enabled and the command line when Echo is enabled. ErrNoMain is
+// returned if there is nothing to run (see DefaultMain).
+func (c *Cmd) argv() ([]string, error) { return c.argvTo(c.stdout()) }
+
+// argvTo is the same as argv but for standard output written to out
+// (see AutoColor).
+func (c *Cmd) argvTo(out io.Writer) ([]string, error) {
+	if !c.hasMain() && DefaultMain == "" && !c.terminal() {
+		return nil, ErrNoMain
+	}
+	args := hookArgv(c.args(out))
+	if Echo || c.Echo {
+		Logger.Println(shellJoin(args))
+	}
+	if Trace {
+		main, cached := c.main()
+		Logger.Printf("java: main %q (cached: %v)", main, cached)
+		Logger.Printf("java: argv %q", args)
+		Logger.Printf("java: CLASSPATH=%v", os.Getenv("CLASSPATH"))
+	}
+	return args, nil
+}
+
+// hookArgv returns args after passing them through the ArgvHook (if
+// set).
+func hookArgv(args []string) []string {
+	if ArgvHook == nil {
+		return args
+	}
+	return ArgvHook(args)
+}
+
 // Exec takes the command line arguments to be passed to the first
 // "java" command executable found on the local system path. It's
 // usefulness is that it will automatically check for any extracted
@@ -133,7 +928,8 @@ func Class2Path(cl string) string {
 // those that have values separated by space, and since Java
 // implementation may have different options completely, this function
 // requires that all options begin with dash (-) and use one of the
-// no-space forms for making the value assignment (-Dfoo=bar, -foo:bar).
+// no-space forms for making the value assignment (-Dfoo=bar, -foo:bar)
+// unless listed in ValueOptions (-cp foo.jar).
 //
 // This first argument to not begin with a dash is used as the class
 // name, jar, or java file.
@@ -141,39 +937,409 @@ func Class2Path(cl string) string {
 // All arguments after the main class/jar/java argument are passed as
 // arguments to the main argument itself.
 func Exec(cmd ...string) error {
-	c := ParseCmd(cmd...)
-	main := c.Name
+	args, err := argv(cmd...)
+	if err != nil {
+		return err
+	}
+	return execArgv(args...)
+}
+
+// ExecReplace is the same as Exec but replaces the current process with
+// java (see internal.SysExec) so that it never returns unless there is
+// an error. This hands the terminal over fully to interactive Java
+// programs. On systems that cannot replace the process (Windows) it is
+// the same as Exec.
+func ExecReplace(cmd ...string) error {
+	args, err := argv(cmd...)
+	if err != nil {
+		return err
+	}
+	return internal.SysExec(args...)
+}
+
+// Out is the same as Exec but returns the standard output as a string
+// and logs any errors (including ErrOutputTooLarge, see
+// MaxOutputBytes).
+func Out(cmd ...string) string {
+	args, err := argv(cmd...)
+	if err != nil {
+		Logger.Println(err)
+		return ""
+	}
+	return out(args...)
+}
+
+// out runs args (see internal.CommandContext) and returns its standard
+// output logging any error (to Logger) and observing MaxOutputBytes.
+func out(args ...string) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := internal.CommandContext(ctx, args...)
+	if err != nil {
+		Logger.Println(err)
+		return ""
+	}
+	buf := &capBuffer{max: MaxOutputBytes, kill: cancel}
+	c.Stdin, c.Stdout, c.Stderr = nil, buf, nil
+	if err := buf.tooLarge(c.Run()); err != nil {
+		Logger.Println(err)
+	}
+	return buf.String()
+}
+
+// ExecOut is the same as Exec but streams the standard output to the
+// io.Writer passed instead of os.Stdout. Nothing is buffered in
+// memory making it preferable to Out for large output.
+func ExecOut(w io.Writer, cmd ...string) error {
+	args, err := argv(cmd...)
+	if err != nil {
+		return err
+	}
+	c, err := internal.Command(args...)
+	if err != nil {
+		return err
+	}
+	c.Stdout = w
+	return run(c)
+}
+
+// OutTee is the same as ExecOut but also returns everything written to
+// w as a string. This is useful for showing output as it arrives while
+// still being able to inspect all of it afterward.
+func OutTee(w io.Writer, cmd ...string) (string, error) {
+	args, err := argv(cmd...)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := internal.CommandContext(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	buf := &capBuffer{max: MaxOutputBytes, kill: cancel}
+	c.Stdout = io.MultiWriter(w, buf)
+	err = buf.tooLarge(run(c))
+	return buf.String(), err
+}
+
+// OutContext is the same as Out but kills the java process when ctx is
+// done and returns any error rather than logging it. On cancellation
+// the output produced so far is returned along with ctx.Err().
+func OutContext(ctx context.Context, cmd ...string) (string, error) {
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, err := ParseCmd(cmd...).command(cctx, nil)
+	if err != nil {
+		return "", err
+	}
+	buf := &capBuffer{max: MaxOutputBytes, kill: cancel}
+	c.Stdout = buf
+	err = buf.tooLarge(run(c))
+	if ctx.Err() != nil {
+		return buf.String(), ctx.Err()
+	}
+	return buf.String(), err
+}
+
+// OutLines runs the command (see Cmd.Output) and returns the standard
+// output split into lines (without any line endings). The final line
+// ending does not produce a trailing empty line.
+func OutLines(cmd ...string) ([]string, error) {
+	out, err := ParseCmd(cmd...).Output()
+	out = strings.TrimSuffix(strings.ReplaceAll(out, "\r\n", "\n"), "\n")
+	if out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), err
+}
+
+// OutFile is the same as ExecOut but writes the standard output to the
+// file at path (creating or truncating it). Any error running the
+// command or writing the file is returned.
+func OutFile(path string, cmd ...string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := ExecOut(f, cmd...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ExecScan is the same as Exec but calls onStdout and onStderr (when
+// not nil) with each line (without line ending) of the standard output
+// and error as they are produced. Each stream is scanned concurrently in
+// its own goroutine so a slow callback for one never blocks the other.
+// Any output following a line too long for the scanner is discarded
+// rather than allowed to fill the pipe and hang the java process. Both
+// goroutines have completed before ExecScan returns.
+func ExecScan(onStdout, onStderr func(line string), cmd ...string) error {
+	c, err := ParseCmd(cmd...).command(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	c.Stdout, c.Stderr = nil, nil
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := c.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := c.Start(); err != nil {
+		return err
+	}
+	tail := &tailBuffer{max: maxStderr}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go scanLines(&wg, stdout, onStdout)
+	go scanLines(&wg, io.TeeReader(stderr, tail), onStderr)
+	wg.Wait()
+	err = c.Wait()
+	return execError(c, tail.String(), err)
+}
 
-	if strings.HasSuffix(c.Name, ".java") || strings.HasSuffix(c.Name, ".jar") {
-		if c := Cached(c.Name); c != "" {
-			main = c
+// scanLines calls fn with every line read from r (if fn is not nil) and
+// then drains whatever remains of r so that the writer never blocks.
+func scanLines(wg *sync.WaitGroup, r io.Reader, fn func(string)) {
+	defer wg.Done()
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if fn != nil {
+			fn(strings.TrimSuffix(s.Text(), "\r"))
 		}
 	}
+	io.Copy(io.Discard, r)
+}
 
-	args := []string{"java"}
-	args = append(args, c.Options...)
-	args = append(args, main)
-	args = append(args, c.Args...)
+// FindAndRun is the same as Exec but for a class known only by its
+// simple name (HelloWorld) which is searched for in the CacheDir (and
+// SystemCacheDir) within any package directory. The fully qualified
+// name (see Path2Class) of the one class file found is run. An error
+// is returned if none (wrapping fs.ErrNotExist) or more than one is
+// found.
+func FindAndRun(simpleName string, args ...string) error {
+	found := cachedClasses(simpleName)
+	switch len(found) {
+	case 0:
+		return fmt.Errorf("class %v in cache: %w", simpleName, _fs.ErrNotExist)
+	case 1:
+		return Exec(append([]string{found[0]}, args...)...)
+	}
+	return fmt.Errorf("class %v is ambiguous: %v", simpleName, found)
+}
+
+// cachedClasses returns the fully qualified names (see Path2Class) of
+// every class within the CacheDir (and SystemCacheDir) with the given
+// simple name.
+func cachedClasses(simpleName string) []string {
+	seen := map[string]bool{}
+	var found []string
+	for _, path := range findClasses(simpleName) {
+		for _, dir := range []string{CacheDir, SystemCacheDir} {
+			rel, err := filepath.Rel(dir, path)
+			if dir == "" || err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			if class := Path2Class(rel); !seen[class] {
+				seen[class] = true
+				found = append(found, class)
+			}
+			break
+		}
+	}
+	return found
+}
 
-	return internal.Exec(args...)
+// InferPackage enables running a class by its simple name (HelloWorld)
+// when it is actually within a package in the cache (foo.HelloWorld).
+// When set, a bare class name that cannot be found (see Locate) is
+// replaced by the fully qualified name of the one cached class with
+// that simple name (see FindAndRun). Nothing is changed if there is
+// none or more than one. It is off by default to keep the strict
+// behavior of java itself.
+var InferPackage bool
+
+// inferPackage returns the fully qualified name to use for the class
+// name (see InferPackage) or name unchanged.
+func inferPackage(name string) string {
+	if !InferPackage || strings.Contains(name, ".") || Kind(name) != "class" {
+		return name
+	}
+	if _, err := Locate(name); err == nil {
+		return name
+	}
+	if found := cachedClasses(name); len(found) == 1 {
+		return found[0]
+	}
+	return name
 }
 
-// Out is the same as Exec but returns the standard output as a string
-// and logs any errors.
-func Out(cmd ...string) string {
-	c := ParseCmd(cmd...)
-	main := c.Name
+// RunSource is the same as Exec but for a single ".java" source file
+// at path (which is resolved against the cache). It returns ErrNoJDK
+// if no javac is found since the source launcher requires a full JDK.
+func RunSource(path string, args ...string) error {
+	if !HasJavac() {
+		return ErrNoJDK
+	}
+	return Exec(append([]string{path}, args...)...)
+}
 
-	if strings.HasSuffix(c.Name, ".java") || strings.HasSuffix(c.Name, ".jar") {
-		if c := Cached(c.Name); c != "" {
-			main = c
+// ExecScript runs the jshell script (usually ending in ".jsh") at path
+// with the first "jshell" command found on the system path, preferring
+// any cached version of it (see Cached). Since jshell scripts cannot
+// receive arguments of their own, any args are passed to jshell itself
+// before the script (-R-Dfoo=bar, --class-path=foo.jar). Note that the
+// script should end with /exit or jshell will remain interactive. The
+// ArgvHook, Echo, and Trace apply as they do for java.
+func ExecScript(path string, args ...string) error {
+	c := &Cmd{Executable: "jshell", Options: args, Name: path}
+	argv, err := c.argv()
+	if err != nil {
+		return err
+	}
+	return execArgv(argv...)
+}
+
+// command returns the internal.CommandContext for the Cmd with its
+// standard output and error connected to Stdout and Stderr (when not
+// nil) and MergeStderr applied. The out is where the caller will send
+// the standard output (nil for Stdout or os.Stdout) and decides the
+// AutoColor.
+func (c *Cmd) command(ctx context.Context, out io.Writer) (*exec.Cmd, error) {
+	if out == nil {
+		out = c.stdout()
+	}
+	args, err := c.argvTo(out)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := internal.CommandContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	if c.Stdout != nil {
+		cmd.Stdout = c.Stdout
+	}
+	if c.Stderr != nil {
+		cmd.Stderr = c.Stderr
+	}
+	if c.LogPrefix != "" {
+		cmd.Stdout = &prefixWriter{w: cmd.Stdout, prefix: c.LogPrefix}
+		cmd.Stderr = &prefixWriter{w: cmd.Stderr, prefix: c.LogPrefix}
+	}
+	if c.MergeStderr {
+		cmd.Stderr = cmd.Stdout
+	}
+	if c.ProgramName != "" {
+		cmd.Args[0] = c.ProgramName
+	}
+	if c.Umask != 0 {
+		if err := wrapUmask(cmd, c.Umask); err != nil {
+			return nil, err
 		}
 	}
+	cmd.ExtraFiles = c.ExtraFiles
+	return cmd, nil
+}
 
-	args := []string{"java"}
-	args = append(args, c.Options...)
-	args = append(args, main)
-	args = append(args, c.Args...)
+// ErrTimeout is returned when a Cmd runs longer than its Timeout.
+var ErrTimeout = errors.New("java timed out")
+
+// withTimeout returns a context derived from ctx with the Timeout (if
+// any) applied.
+func (c *Cmd) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout > 0 {
+		return context.WithTimeout(ctx, c.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// timedOut returns ErrTimeout instead of err if the Timeout (rather
+// than the parent context) caused tctx to end.
+func (c *Cmd) timedOut(parent, tctx context.Context, err error) error {
+	if err != nil && c.Timeout > 0 && parent.Err() == nil &&
+		tctx.Err() == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+	return err
+}
+
+// Run is the same as Exec but for an already created Cmd. The Stdout
+// and Stderr buffers (if not nil) receive the output of the java
+// process instead of os.Stdout and os.Stderr.
+func (c *Cmd) Run() error { return c.RunContext(context.Background()) }
+
+// RunContext is the same as Run but kills the java process when ctx is
+// done. When both ctx and the Timeout have deadlines the earliest one
+// applies. ErrTimeout is returned only if the Timeout was the cause
+// (otherwise the error from the java process is returned and ctx.Err()
+// can be checked).
+func (c *Cmd) RunContext(ctx context.Context) error {
+	tctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	cmd, err := c.command(tctx, nil)
+	if err != nil {
+		return err
+	}
+	return c.timedOut(ctx, tctx, run(cmd))
+}
 
-	return internal.Out(args...)
+// Output runs the Cmd and returns its standard output as a string (which
+// includes the standard error when MergeStderr is set). Unlike Out, any
+// error is returned rather than logged. Stdout (if not nil) also
+// receives the output. The Timeout is observed as with Run and so is
+// MaxOutputBytes.
+func (c *Cmd) Output() (string, error) {
+	ctx := context.Background()
+	tctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	buf := &capBuffer{max: c.maxOutput(), kill: cancel}
+	cmd, err := c.command(tctx, buf)
+	if err != nil {
+		return "", err
+	}
+	if c.Stdout != nil {
+		cmd.Stdout = io.MultiWriter(buf, c.Stdout)
+	} else {
+		cmd.Stdout = buf
+	}
+	if c.MergeStderr {
+		cmd.Stderr = cmd.Stdout
+	}
+	err = c.timedOut(ctx, tctx, run(cmd))
+	return buf.String(), buf.tooLarge(err)
+}
+
+// Run is the maximal information variant of Exec returning the
+// standard output, the standard error, and the exit code of the java
+// process as well as any error (including an *ExecError for a non-zero
+// exit). Both streams are drained concurrently so large output cannot
+// cause a deadlock. The code is -1 if java could not be started at all.
+// Each stream is limited to MaxOutputBytes.
+func Run(cmd ...string) (stdout, stderr string, code int, err error) {
+	args, err := argv(cmd...)
+	if err != nil {
+		return "", "", -1, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := internal.CommandContext(ctx, args...)
+	if err != nil {
+		return "", "", -1, err
+	}
+	outbuf := &capBuffer{max: MaxOutputBytes, kill: cancel}
+	errbuf := &capBuffer{max: MaxOutputBytes, kill: cancel}
+	c.Stdout, c.Stderr = outbuf, errbuf
+	err = c.Run()
+	code = -1
+	if c.ProcessState != nil {
+		code = c.ProcessState.ExitCode()
+	}
+	err = errbuf.tooLarge(outbuf.tooLarge(execError(c, errbuf.String(), err)))
+	return outbuf.String(), errbuf.String(), code, err
 }
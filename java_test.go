@@ -1,11 +1,19 @@
 package java_test
 
 import (
+	"bytes"
+	"context"
 	"embed"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/rwxrob/fs/file"
 	"github.com/rwxrob/java"
@@ -27,6 +35,55 @@ func ExampleClass2Path() {
 	// foo/bar/Some.class
 }
 
+func ExamplePath2Class() {
+
+	fmt.Println(java.Path2Class("foo/bar/Some.class"))
+	fmt.Println(java.Path2Class("Some.class"))
+
+	// Output:
+	// foo.bar.Some
+	// Some
+}
+
+func ExampleFindAndRun() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	os.MkdirAll("testdata/tmpcache/foo", 0700)
+	os.MkdirAll("testdata/tmpcache/bar", 0700)
+	os.WriteFile("testdata/tmpcache/foo/Tool.class", nil, 0600)
+	os.WriteFile("testdata/tmpcache/bar/Tool.class", nil, 0600)
+
+	fmt.Println(java.FindAndRun("Nope"))
+	fmt.Println(java.FindAndRun("Tool"))
+
+	// Output:
+	// class Nope in cache: file does not exist
+	// class Tool is ambiguous: [bar.Tool foo.Tool]
+}
+
+func ExampleInferPackage() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	os.MkdirAll("testdata/tmpcache/foo", 0700)
+	os.MkdirAll("testdata/tmpcache/bar", 0700)
+	os.WriteFile("testdata/tmpcache/foo/Tool.class", nil, 0600)
+	os.WriteFile("testdata/tmpcache/bar/Tool.class", nil, 0600)
+	os.WriteFile("testdata/tmpcache/foo/Other.class", nil, 0600)
+
+	fmt.Println(java.ParseCmd("Other").Argv())
+	java.InferPackage = true
+	defer func() { java.InferPackage = false }()
+	fmt.Println(java.ParseCmd("Other").Argv())
+	fmt.Println(java.ParseCmd("Tool").Argv())
+
+	// Output:
+	// [java Other]
+	// [java foo.Other]
+	// [java Tool]
+}
+
 func ExampleParseCmd() {
 
 	c := `-Dfoo=bar HelloClass some args here`
@@ -42,6 +99,83 @@ func ExampleParseCmd() {
 	// [some args here]
 }
 
+func ExampleParseCmd_order() {
+
+	c := java.ParseCmd(
+		"-XX:+UseG1GC", "-cp", "b.jar", "-Dx=1", "-XX:-UseG1GC", "-Dx=2",
+		"Main", "z", "-a", "y", "-b",
+	)
+
+	fmt.Println(c.Options)
+	fmt.Println(c.Args)
+	fmt.Println(c.Argv())
+
+	// typed options too
+	c = java.ParseCmd(
+		"-XX:MaxRAMPercentage=50", "-javaagent:/opt/a.jar", "-Dx=1",
+		"--add-opens", "m/p=ALL-UNNAMED", "-XX:+UseZGC", "-XX:-UseZGC",
+		"-XX:MaxRAMPercentage=75", "Main",
+	)
+
+	fmt.Println(c.Options)
+	fmt.Println(c.MaxRAMPercentage, c.Agents, c.AddOpens, c.UseZGC)
+	fmt.Println(c.Argv())
+
+	// Output:
+	// [-XX:+UseG1GC -cp b.jar -Dx=1 -XX:-UseG1GC -Dx=2]
+	// [z -a y -b]
+	// [java -XX:+UseG1GC -cp b.jar -Dx=1 -XX:-UseG1GC -Dx=2 Main z -a y -b]
+	// [-XX:MaxRAMPercentage=50 -javaagent:/opt/a.jar -Dx=1 --add-opens m/p=ALL-UNNAMED -XX:+UseZGC -XX:-UseZGC -XX:MaxRAMPercentage=75]
+	// 75 [/opt/a.jar] [m/p=ALL-UNNAMED] false
+	// [java -XX:MaxRAMPercentage=50 -javaagent:/opt/a.jar -Dx=1 --add-opens m/p=ALL-UNNAMED -XX:+UseZGC -XX:-UseZGC -XX:MaxRAMPercentage=75 Main]
+}
+
+func ExampleParseCmd_unrecognized() {
+
+	c := java.ParseCmd("-cp", "lib/foo.jar", "-", "--add-modules", "-Dx=y", "Main", "arg")
+
+	fmt.Println(c.Name)
+	fmt.Println(c.Options)
+	fmt.Println(c.Unrecognized)
+	fmt.Println(c.Args)
+
+	// Output:
+	// Main
+	// [-cp lib/foo.jar - --add-modules -Dx=y]
+	// [- --add-modules]
+	// [arg]
+}
+
+func ExampleParseCmd_maxRAMPercentage() {
+
+	c := java.ParseCmd("-XX:MaxRAMPercentage=62.5", "-Dfoo=bar", "Server")
+
+	fmt.Println(c.MaxRAMPercentage)
+	fmt.Println(c.Options)
+	fmt.Println(c.Argv())
+
+	// Output:
+	// 62.5
+	// [-XX:MaxRAMPercentage=62.5 -Dfoo=bar]
+	// [java -XX:MaxRAMPercentage=62.5 -Dfoo=bar Server]
+}
+
+func ExampleParseCmd_argfile() {
+
+	c := java.ParseCmd("@testdata/opts.argfile", "@@Main", "@arg")
+
+	fmt.Println(c.Name)
+	fmt.Println(c.Options)
+	fmt.Println(c.Unrecognized)
+	fmt.Println(c.Args)
+
+	// Output:
+	// @Main
+	// [-Dfoo=bar -cp lib/foo.jar -Xmx1g @testdata/opts.argfile]
+	// [@testdata/opts.argfile]
+	// [@arg]
+}
+
 func ExampleExtract() {
 
 	java.CacheDir = "testdata/tmpcache"
@@ -64,6 +198,59 @@ func ExampleExtract() {
 
 }
 
+func ExampleExtractProgress() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+
+	err := java.ExtractProgress(javafiles, "testdata/javafiles",
+		func(path string, n, total int) {
+			fmt.Println(n, total, path)
+		})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// 1 5 testdata/tmpcache/HelloWorld.class
+	// 2 5 testdata/tmpcache/fooprop.java
+	// 3 5 testdata/tmpcache/greeting.java
+	// 4 5 testdata/tmpcache/hello.java
+	// 5 5 testdata/tmpcache/hello.jsh
+}
+
+func ExampleExtract_missing_root() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+
+	err := java.Extract(javafiles, "testdata/javafile")
+	fmt.Println(err)
+
+	// Output:
+	// embed root "testdata/javafile" not found
+}
+
+func ExampleExtractFile() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+
+	path, err := java.ExtractFile(javafiles, "testdata/javafiles/hello.java")
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(path)
+	fmt.Println(java.Cached("hello.java"))
+	fmt.Println(java.Cached("HelloWorld.class"))
+
+	// Output:
+	// testdata/tmpcache/hello.java
+	// testdata/tmpcache/hello.java
+	//
+}
+
 func ExampleExec_java() {
 
 	err := java.Exec("testdata/javafiles/hello.java")
@@ -100,7 +287,7 @@ func ExampleExec_class_nocache() {
 	// Hello, World!
 }
 
-func ExampleExec_class_Cached() {
+func ExampleExec_class_cached() {
 
 	java.CacheDir = "testdata/tmpcache"
 	defer os.RemoveAll("testdata/tmpcache")
@@ -117,6 +304,70 @@ func ExampleExec_class_Cached() {
 	// Hello, World!
 }
 
+func ExampleExecScript() {
+
+	err := java.ExecScript("testdata/javafiles/hello.jsh")
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// Hello, World!
+}
+
+func ExampleRunSource() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/fakejava")
+	os.Setenv("PATH", bin)
+
+	err := java.RunSource("testdata/javafiles/hello.java")
+	fmt.Println(errors.Is(err, java.ErrNoJDK), err)
+
+	// Output:
+	// true javac not found next to java (JDK required, see HasJavac)
+}
+
+func ExampleExecScript_argvHook() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", "")
+
+	java.Echo = true
+	java.Logger.SetOutput(os.Stdout)
+	java.Logger.SetFlags(0)
+	defer func() {
+		java.Echo = false
+		java.ArgvHook = nil
+		java.Logger.SetOutput(os.Stderr)
+		java.Logger.SetFlags(log.LstdFlags)
+	}()
+
+	java.ArgvHook = func(argv []string) []string {
+		return append([]string{argv[0], "-R-Dcorp.env=prod"}, argv[1:]...)
+	}
+	err := java.ExecScript("testdata/javafiles/hello.jsh", "-q")
+	fmt.Println(err)
+
+	// Output:
+	// jshell -R-Dcorp.env=prod -q testdata/javafiles/hello.jsh
+	// exec: "jshell": executable file not found in $PATH
+}
+
+func ExampleExecReplace() {
+
+	// the test binary calls ExecReplace with its args (see TestMain)
+	exe, _ := os.Executable()
+	bin, _ := filepath.Abs("testdata/runtime/bin")
+	cmd := exec.Command(exe, "-Dx=y", "Main", "arg")
+	cmd.Env = append(os.Environ(), "GOJAVA_HELPER=replace", "PATH="+bin)
+	out, err := cmd.Output()
+	fmt.Printf("%q %v\n", out, err)
+
+	// Output:
+	// "-Dx=y Main arg\n" <nil>
+}
+
 func ExampleOut_java_with_Args() {
 
 	out := java.Out("-Dfoo=bar", "testdata/javafiles/fooprop.java")
@@ -143,3 +394,439 @@ func ExampleOut_jar() {
 	// Output:
 	// Hello, World!
 }
+
+func ExampleCmd_Argv() {
+
+	c := java.ParseCmd("-Dfoo=bar", "HelloWorld", "some", "arg")
+	fmt.Println(c.Argv())
+
+	// Output:
+	// [java -Dfoo=bar HelloWorld some arg]
+}
+
+func ExampleOutFile() {
+
+	defer os.Remove("testdata/out.txt")
+
+	err := java.OutFile("testdata/out.txt", "-jar", "testdata/files.jar")
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	buf, _ := os.ReadFile("testdata/out.txt")
+	fmt.Print(string(buf))
+
+	// Output:
+	// Hello, World!
+}
+
+func ExampleExecScan() {
+
+	onOut := func(line string) { fmt.Println("out:", line) }
+	err := java.ExecScan(onOut, nil, "-jar", "testdata/files.jar")
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// out: Hello, World!
+}
+
+func ExampleArgvHook() {
+
+	java.Echo = true
+	java.Logger.SetOutput(os.Stdout)
+	java.Logger.SetFlags(0)
+	defer func() {
+		java.Echo = false
+		java.ArgvHook = nil
+		java.Logger.SetOutput(os.Stderr)
+		java.Logger.SetFlags(log.LstdFlags)
+	}()
+
+	java.ArgvHook = func(argv []string) []string {
+		return append([]string{argv[0], "-Dcorp.env=prod"}, argv[1:]...)
+	}
+	java.Exec("-Dx=y", "testdata/nope.jar")
+
+	// Output:
+	// java -Dcorp.env=prod -Dx=y testdata/nope.jar
+}
+
+func ExampleDefaultMain() {
+
+	fmt.Println(java.Exec("-Dx=y"))
+
+	java.DefaultMain = "com.example.Main"
+	defer func() { java.DefaultMain = "" }()
+	fmt.Println(java.ParseCmd("-Dx=y").Argv())
+
+	// Output:
+	// no main class, jar, or module given
+	// [java -Dx=y com.example.Main]
+}
+
+func ExampleTerminalOptions() {
+
+	java.Echo = true
+	java.DefaultMain = "com.example.Main"
+	java.Logger.SetOutput(os.Stdout)
+	java.Logger.SetFlags(0)
+	defer func() {
+		java.Echo = false
+		java.DefaultMain = ""
+		java.Logger.SetOutput(os.Stderr)
+		java.Logger.SetFlags(log.LstdFlags)
+	}()
+
+	fmt.Println(java.ParseCmd("-version").Argv())
+	fmt.Println(java.ParseCmd("-Dx=y", "--help").Argv())
+	java.Exec("-version")
+
+	// Output:
+	// [java -version]
+	// [java -Dx=y --help]
+	// java -version
+}
+
+func ExampleTrace() {
+
+	java.Trace = true
+	java.Logger.SetOutput(os.Stdout)
+	java.Logger.SetFlags(0)
+	defer func() {
+		java.Trace = false
+		java.Logger.SetOutput(os.Stderr)
+		java.Logger.SetFlags(log.LstdFlags)
+	}()
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	os.Setenv("CLASSPATH", "testdata/javafiles")
+	java.CacheDir = "testdata/tmpcache"
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/failjava")
+	os.Setenv("PATH", bin)
+
+	java.Out("-Dfoo=bar", "Nothing.jar")
+
+	// Output:
+	// java: main "Nothing.jar" (cached: false)
+	// java: argv ["java" "-Dfoo=bar" "Nothing.jar"]
+	// java: CLASSPATH=testdata/javafiles
+	// exit status 3
+}
+
+func ExampleCmd_Clone() {
+
+	orig := java.ParseCmd("-Dfoo=bar", "-Xmx1g", "HelloWorld", "some")
+	orig.Options = orig.Options[:1] // leave spare capacity
+
+	clone := orig.Clone()
+	clone.Options = append(clone.Options, "-Dother=thing")
+	clone.Args[0] = "changed"
+
+	fmt.Println(orig.Argv())
+	fmt.Println(clone.Argv())
+
+	// Output:
+	// [java -Xmx1g -Dfoo=bar HelloWorld some]
+	// [java -Xmx1g -Dfoo=bar -Dother=thing HelloWorld changed]
+}
+
+func ExampleCmd_Argv_locale() {
+
+	c := java.ParseCmd("Report", "out.pdf")
+	c.Locale = "fr-CA"
+	c.Timezone = "UTC"
+	fmt.Println(c.Argv())
+
+	c = java.ParseCmd(
+		"-Duser.timezone=UTC", "-Duser.language=de", "-Duser.country=DE",
+		"-Dx=y", "Report",
+	)
+	fmt.Println(c.Timezone, c.Locale == "")
+	c.Timezone = "America/New_York"
+	c.Locale = "en_US"
+	fmt.Println(c.Argv())
+	c.Locale = "fr"
+	fmt.Println(c.Argv())
+
+	// Output:
+	// [java -Duser.language=fr -Duser.country=CA -Duser.timezone=UTC Report out.pdf]
+	// UTC true
+	// [java -Duser.timezone=America/New_York -Duser.language=en -Duser.country=US -Dx=y Report]
+	// [java -Duser.timezone=America/New_York -Duser.language=fr -Dx=y Report]
+}
+
+func ExampleOutTee() {
+
+	out, err := java.OutTee(os.Stdout, "-jar", "testdata/files.jar")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(strings.ToUpper(out))
+
+	// Output:
+	// Hello, World!
+	// HELLO, WORLD!
+}
+
+func ExampleRun() {
+
+	stdout, stderr, code, err := java.Run("-jar", "testdata/files.jar")
+	fmt.Printf("%q %q %v %v\n", stdout, stderr, code, err)
+
+	_, _, code, err = java.Run("-jar", "testdata/nope.jar")
+	fmt.Println(code, err)
+
+	// Output:
+	// "Hello, World!\n" "" 0 <nil>
+	// 1 exit status 1: Error: Unable to access jarfile testdata/nope.jar
+}
+
+func ExampleCmd_Run() {
+
+	c := java.ParseCmd("-jar", "testdata/files.jar")
+	c.Stdout = new(bytes.Buffer)
+
+	if err := c.Run(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%q\n", c.Stdout.String())
+
+	// Output:
+	// "Hello, World!\n"
+}
+
+func ExampleCmd_ProgramName() {
+
+	// the test binary stands in for java (see TestMain)
+	exe, _ := os.Executable()
+	os.Setenv("GOJAVA_HELPER", "argv0")
+	defer os.Unsetenv("GOJAVA_HELPER")
+
+	c := &java.Cmd{Name: "Main", Executable: exe, ProgramName: "myapp"}
+	out, err := c.Output()
+	fmt.Printf("%q %v\n", out, err)
+
+	// Output:
+	// "myapp\n" <nil>
+}
+
+func ExampleCmd_ExtraFiles() {
+
+	r, w, _ := os.Pipe()
+	w.WriteString("from fd 3\n")
+	w.Close()
+	defer r.Close()
+
+	c := &java.Cmd{
+		Name:       "Main",
+		Executable: "testdata/fd3java/java",
+		ExtraFiles: []*os.File{r},
+	}
+	out, err := c.Output()
+	fmt.Printf("%q %v\n", out, err)
+
+	// Output:
+	// "from fd 3\n" <nil>
+}
+
+func ExampleCmd_Argv_autoColor() {
+
+	c := java.ParseCmd("Tool", "report")
+	c.AutoColor = "--no-color"
+	c.Stdout = new(bytes.Buffer)
+	fmt.Println(c.Argv())
+
+	// always added when captured by Output
+	c.Stdout = nil
+	c.Executable = "testdata/runtime/bin/java"
+	out, err := c.Output()
+	fmt.Print(out, err, "\n")
+
+	// Output:
+	// [java Tool report --no-color]
+	// Tool report --no-color
+	// <nil>
+}
+
+func ExampleOutLines() {
+
+	lines, err := java.OutLines("-jar", "testdata/files.jar")
+	fmt.Printf("%q %v\n", lines, err)
+
+	// Output:
+	// ["Hello, World!"] <nil>
+}
+
+func ExampleKind() {
+
+	for _, it := range []string{
+		"foo.jar", "Hello.java", "setup.jsh", "java.base.jmod",
+		"foo/bar/Some.class", "foo.bar.Some", "HelloWorld", "testdata",
+		"lib/classes",
+	} {
+		fmt.Println(java.Kind(it))
+	}
+
+	// Output:
+	// jar
+	// java
+	// jsh
+	// jmod
+	// class
+	// class
+	// class
+	// path
+	// path
+}
+
+func ExampleCmd_MarshalJSON() {
+
+	c := java.ParseCmd("-XX:+ExitOnOutOfMemoryError", "-Dfoo=bar", "Main", "arg")
+	c.Timezone = "UTC"
+	c.Stdout = new(bytes.Buffer)
+
+	buf, err := json.Marshal(c)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(buf))
+
+	n := new(java.Cmd)
+	if err := json.Unmarshal(buf, n); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(n.Argv())
+
+	// Output:
+	// {"Name":"Main","Options":["-XX:+ExitOnOutOfMemoryError","-Dfoo=bar"],"Args":["arg"],"Timezone":"UTC","ExitOnOOM":true}
+	// [java -Duser.timezone=UTC -XX:+ExitOnOutOfMemoryError -Dfoo=bar Main arg]
+}
+
+func ExampleCmd_String() {
+
+	c := java.ParseCmd("-Dmsg=hello world", "Main", "it's")
+	fmt.Println(c)
+
+	// Output:
+	// java '-Dmsg=hello world' Main 'it'\''s'
+}
+
+func ExampleEcho() {
+
+	java.Echo = true
+	java.Logger.SetOutput(os.Stdout)
+	java.Logger.SetFlags(0)
+	defer func() {
+		java.Echo = false
+		java.Logger.SetOutput(os.Stderr)
+		java.Logger.SetFlags(log.LstdFlags)
+	}()
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/failjava")
+	os.Setenv("PATH", bin)
+
+	java.Out("-Dfoo=bar", "Nothing")
+
+	// Output:
+	// java -Dfoo=bar Nothing
+	// exit status 3
+}
+
+func ExampleCmd_NormalizeName() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	if err := java.Extract(javafiles, "testdata/javafiles"); err != nil {
+		fmt.Println(err)
+	}
+
+	for _, it := range []string{" HelloWorld ", "foo.bar.Some.class", "hello.java"} {
+		c := &java.Cmd{Name: it}
+		c.NormalizeName()
+		fmt.Printf("%q\n", c.Name)
+	}
+
+	// Output:
+	// "HelloWorld"
+	// "foo.bar.Some"
+	// "testdata/tmpcache/hello.java"
+}
+
+func ExampleOutContext() {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out, err := java.OutContext(ctx, "-jar", "testdata/files.jar")
+	fmt.Printf("%q %v\n", out, err)
+
+	// Output:
+	// "" context canceled
+}
+
+func ExampleCmd_Timeout() {
+
+	c := &java.Cmd{
+		Name:       "Main",
+		Executable: "testdata/slowjava/java",
+		Timeout:    100 * time.Millisecond,
+	}
+
+	err := c.Run()
+	fmt.Println(errors.Is(err, java.ErrTimeout))
+
+	out, err := c.Output()
+	fmt.Printf("%q %v\n", out, errors.Is(err, java.ErrTimeout))
+
+	// the parent context ending first is not a timeout
+	c.Timeout = 10 * time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	err = c.RunContext(ctx)
+	fmt.Println(err != nil, errors.Is(err, java.ErrTimeout), ctx.Err())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = c.RunContext(ctx)
+	fmt.Println(err != nil, errors.Is(err, java.ErrTimeout), ctx.Err())
+
+	// Output:
+	// started
+	// true
+	// "started\n" true
+	// started
+	// true false context canceled
+	// started
+	// true false context deadline exceeded
+}
+
+func ExampleCmd_MergeStderr() {
+
+	// fakejava only writes to standard error
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	c := &java.Cmd{
+		Name:        "Main",
+		Executable:  "testdata/fakejava/java",
+		Stdout:      stdout,
+		Stderr:      stderr,
+		MergeStderr: true,
+	}
+	c.Run()
+	fmt.Println(strings.Contains(stdout.String(), "openjdk version"), stderr.Len())
+
+	c.Stdout, c.Stderr = nil, nil
+	out, _ := c.Output()
+	fmt.Println(strings.Split(out, "\n")[0])
+
+	c.MergeStderr = false
+	c.Stderr = stderr
+	out, _ = c.Output()
+	fmt.Printf("%q %v\n", out, strings.Contains(stderr.String(), "openjdk version"))
+
+	// Output:
+	// true 0
+	// Property settings:
+	// "" true
+}
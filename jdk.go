@@ -0,0 +1,196 @@
+package java
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rwxrob/fs/file"
+)
+
+// ErrNoJDK is returned when something requires a full JDK (javac) but
+// only a JRE was found on the host.
+var ErrNoJDK = errors.New("javac not found next to java (JDK required, see HasJavac)")
+
+// javaBin returns the real directory containing the first java
+// executable found on the system path (following any symbolic links
+// such as those created by update-alternatives).
+func javaBin() (string, error) {
+	path, err := exec.LookPath("java")
+	if err != nil {
+		return "", err
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
+// sibling returns the full path to the named executable in the same
+// directory as the java executable or an empty string if not found.
+func sibling(name string) string {
+	dir, err := javaBin()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(dir, name)
+	if !file.Exists(path) {
+		return ""
+	}
+	return path
+}
+
+// HasJavac returns true if a javac executable exists in the same
+// directory as the java executable indicating a full JDK (rather than
+// just a JRE) is installed. A JDK is required to compile or to run
+// ".java" source files directly.
+func HasJavac() bool { return sibling("javac") != "" }
+
+// HasJDK is an alias for HasJavac.
+func HasJDK() bool { return HasJavac() }
+
+// Version returns the feature (major) version of the java on the
+// system path (8 for 1.8.0_292, 17 for 17.0.2) as reported by java
+// -version.
+func Version() (int, error) { return versionOf("java") }
+
+// versionRegx matches the quoted version reported by java -version.
+var versionRegx = regexp.MustCompile(`version "(1\.)?(\d+)`)
+
+// versionOf returns the feature version of the given java executable.
+func versionOf(java string) (int, error) {
+	out, err := exec.Command(java, "-version").CombinedOutput()
+	if err != nil {
+		return 0, err
+	}
+	m := versionRegx.FindSubmatch(out)
+	if m == nil {
+		return 0, fmt.Errorf("%v: unable to determine version", java)
+	}
+	return strconv.Atoi(string(m[2]))
+}
+
+var (
+	versionCache   = map[string]int{}
+	versionCacheMu sync.Mutex
+)
+
+// cachedVersion is the same as versionOf but only runs each java
+// executable (by full path) once.
+func cachedVersion(java string) (int, error) {
+	path, err := exec.LookPath(java)
+	if err != nil {
+		return 0, err
+	}
+	versionCacheMu.Lock()
+	defer versionCacheMu.Unlock()
+	if ver, has := versionCache[path]; has {
+		return ver, nil
+	}
+	ver, err := versionOf(path)
+	if err != nil {
+		return 0, err
+	}
+	versionCache[path] = ver
+	return ver, nil
+}
+
+// JavaFromManager returns the full path to the java executable of the
+// given version as installed by a version manager, checking sdkman
+// (~/.sdkman/candidates/java/<version>/bin/java) and then asdf
+// (~/.asdf/installs/java/<version>/bin/java). Assign it to
+// Cmd.Executable to use it.
+func JavaFromManager(version string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	name := "java"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	for _, dir := range []string{
+		filepath.Join(home, ".sdkman", "candidates", "java"),
+		filepath.Join(home, ".asdf", "installs", "java"),
+	} {
+		path := filepath.Join(dir, version, "bin", name)
+		if file.Exists(path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("java %v not found in sdkman or asdf", version)
+}
+
+// ExecRuntime runs the module (module or module/mainclass) with the
+// java of a custom runtime image (as created by jlink) at imageDir
+// instead of the java on the system path. This allows a trimmed
+// runtime to be shipped and still be driven by this package. An error
+// wrapping fs.ErrNotExist is returned if imageDir has no bin/java.
+func ExecRuntime(imageDir string, module string, args ...string) error {
+	name := "java"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(imageDir, "bin", name)
+	if !file.Exists(path) {
+		return fmt.Errorf("%v: no bin/%v in runtime image: %w",
+			imageDir, name, os.ErrNotExist)
+	}
+	c := &Cmd{Executable: path, Module: module, Args: args}
+	return c.Run()
+}
+
+// MatrixError contains the error (keyed by java executable) of each
+// run of OutMatrix that failed.
+type MatrixError map[string]error
+
+// Error lists each failed java executable (in sorted order) and its
+// error.
+func (e MatrixError) Error() string {
+	var execs []string
+	for it := range e {
+		execs = append(execs, it)
+	}
+	sort.Strings(execs)
+	msgs := make([]string, len(execs))
+	for i, it := range execs {
+		msgs[i] = fmt.Sprintf("%v: %v", it, e[it])
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// OutMatrix runs the command once with each of the java executables
+// (see Cmd.Executable and JavaFromManager) and returns the standard
+// output of each keyed by executable. This makes it easy to confirm
+// that embedded code behaves the same across JDK versions. A failure
+// of one does not stop the others. Instead a MatrixError is returned
+// with all that failed (along with all output).
+func OutMatrix(execs []string, cmd ...string) (map[string]string, error) {
+	outs := map[string]string{}
+	errs := MatrixError{}
+	for _, it := range execs {
+		c := ParseCmd(cmd...)
+		c.Executable = it
+		out, err := c.Output()
+		outs[it] = out
+		if err != nil {
+			errs[it] = err
+		}
+	}
+	if len(errs) > 0 {
+		return outs, errs
+	}
+	return outs, nil
+}
@@ -0,0 +1,52 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleJavaFromManager() {
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", "testdata/home")
+
+	fmt.Println(java.JavaFromManager("temurin-17"))
+	_, err := java.JavaFromManager("21.0.1-tem")
+	fmt.Println(err)
+
+	// Output:
+	// testdata/home/.asdf/installs/java/temurin-17/bin/java <nil>
+	// java 21.0.1-tem not found in sdkman or asdf
+}
+
+func ExampleExecRuntime() {
+
+	defer func(dir string) { java.CacheDir = dir }(java.CacheDir)
+	java.CacheDir = "testdata/cache"
+
+	err := java.ExecRuntime("testdata/runtime", "app/app.Main", "hello")
+	fmt.Println(err)
+
+	fmt.Println(java.ExecRuntime("testdata", "app"))
+
+	// Output:
+	// --module-path testdata/cache -m app/app.Main hello
+	// <nil>
+	// testdata: no bin/java in runtime image: file does not exist
+}
+
+func ExampleOutMatrix() {
+
+	outs, err := java.OutMatrix(
+		[]string{"testdata/nojava1", "testdata/nojava2"},
+		"-jar", "testdata/files.jar",
+	)
+	fmt.Println(len(outs))
+	fmt.Println(err)
+
+	// Output:
+	// 2
+	// testdata/nojava1: exec: "testdata/nojava1": stat testdata/nojava1: no such file or directory; testdata/nojava2: exec: "testdata/nojava2": stat testdata/nojava2: no such file or directory
+}
@@ -0,0 +1,26 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/rwxrob/java"
+)
+
+// TestMain runs the test binary as a stand-in for java (or a program
+// using the package) when re-executed with GOJAVA_HELPER set to what
+// it should do rather than running the tests. Shell script stubs in
+// testdata cannot be used for this since the kernel passes the script
+// path (not the argv[0] of the caller) to the shell.
+func TestMain(m *testing.M) {
+	switch os.Getenv("GOJAVA_HELPER") {
+	case "":
+		os.Exit(m.Run())
+	case "argv0":
+		fmt.Println(os.Args[0])
+	case "replace":
+		fmt.Println("not replaced:", java.ExecReplace(os.Args[1:]...))
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,41 @@
+package java
+
+import (
+	"fmt"
+	"os"
+)
+
+// ExecJarMemfd runs the jar data (usually embedded) with the args
+// without writing it to disk. On Linux the jar is written to an
+// anonymous memory file (memfd_create) which is inherited by the java
+// process as file descriptor 3 and run as /proc/self/fd/3. On other
+// systems (or if memfd_create fails) the jar is written to a temporary
+// file in TempDir instead which is removed once java exits.
+func ExecJarMemfd(data []byte, args ...string) error {
+	f, err := memfd("embedded.jar", data)
+	if err != nil {
+		return execJarTemp(data, args...)
+	}
+	defer f.Close()
+	c := ParseCmd(append([]string{"-jar", "/proc/self/fd/3"}, args...)...)
+	c.ExtraFiles = []*os.File{f}
+	return c.Run()
+}
+
+// execJarTemp writes data to a temporary jar file, runs it with args,
+// and then removes it.
+func execJarTemp(data []byte, args ...string) error {
+	f, err := createTemp("*.jar")
+	if err != nil {
+		return err
+	}
+	defer removeTemp(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing temporary jar: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return Exec(append([]string{"-jar", f.Name()}, args...)...)
+}
@@ -0,0 +1,54 @@
+package java
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// memfdCreate is the memfd_create system call number for each
+// architecture (not all of which are in the syscall package).
+var memfdCreate = map[string]uintptr{
+	"386":      356,
+	"amd64":    319,
+	"arm":      385,
+	"arm64":    279,
+	"loong64":  279,
+	"mips":     4354,
+	"mipsle":   4354,
+	"mips64":   5314,
+	"mips64le": 5314,
+	"ppc64":    360,
+	"ppc64le":  360,
+	"riscv64":  279,
+	"s390x":    350,
+}
+
+// mfdCloexec is MFD_CLOEXEC so that the file is not leaked to other
+// processes (it is still passed to java as one of its ExtraFiles).
+const mfdCloexec = 1
+
+// memfd returns an anonymous memory file (see memfd_create(2)) with the
+// given name containing data.
+func memfd(name string, data []byte) (*os.File, error) {
+	trap, has := memfdCreate[runtime.GOARCH]
+	if !has {
+		return nil, fmt.Errorf("memfd_create not supported on %v", runtime.GOARCH)
+	}
+	cname, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(cname)), mfdCloexec, 0)
+	if errno != 0 {
+		return nil, fmt.Errorf("memfd_create: %w", errno)
+	}
+	f := os.NewFile(fd, name)
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
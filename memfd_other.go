@@ -0,0 +1,13 @@
+//go:build !linux
+
+package java
+
+import (
+	"errors"
+	"os"
+)
+
+// memfd is not supported on this system (see ExecJarMemfd).
+func memfd(name string, data []byte) (*os.File, error) {
+	return nil, errors.New("memfd_create not supported")
+}
@@ -0,0 +1,19 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleExecJarMemfd() {
+
+	data, _ := os.ReadFile("testdata/files.jar")
+	if err := java.ExecJarMemfd(data); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// Hello, World!
+}
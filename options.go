@@ -0,0 +1,672 @@
+package java
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// typedOption relates a typed field of Cmd to the options it is
+// rendered as (and parsed from).
+type typedOption struct {
+
+	// value returns the value of opt for the field and true if opt is
+	// one of its options (nil if the field is never parsed).
+	value func(opt string) (string, bool)
+
+	// set assigns the value of an option to the field (see ParseCmd)
+	// or is nil if the field only takes the place of its options.
+	set func(c *Cmd, v string)
+
+	// render returns the options for the field (nil if it is not set)
+	// with one []string for each entry of a repeat field.
+	render func(c *Cmd) [][]string
+
+	// repeat is true if the option may appear more than once each
+	// adding another entry to the field (Agents) or each taking the
+	// place of another of its options (Locale).
+	repeat bool
+}
+
+// typedOptions are in the order that they are rendered when they are
+// not taking the place of Options (see Cmd.options).
+var typedOptions = []typedOption{
+	{ // Locale
+		value: func(opt string) (string, bool) {
+			if v, ok := prefixValue("-Duser.language=")(opt); ok {
+				return v, ok
+			}
+			return prefixValue("-Duser.country=")(opt)
+		},
+		render: func(c *Cmd) [][]string {
+			if c.Locale == "" {
+				return nil
+			}
+			lang, country, _ := strings.Cut(strings.Replace(c.Locale, "-", "_", 1), "_")
+			opts := [][]string{{"-Duser.language=" + lang}}
+			if country != "" {
+				opts = append(opts, []string{"-Duser.country=" + country})
+			}
+			return opts
+		},
+		repeat: true,
+	},
+	{ // Timezone
+		value: prefixValue("-Duser.timezone="),
+		set:   func(c *Cmd, v string) { c.Timezone = v },
+		render: func(c *Cmd) [][]string {
+			return single(c.Timezone != "", "-Duser.timezone="+c.Timezone)
+		},
+	},
+	{ // ExitOnOOM
+		value: flagValue("ExitOnOutOfMemoryError"),
+		set:   func(c *Cmd, v string) { c.ExitOnOOM = v == "true" },
+		render: func(c *Cmd) [][]string {
+			return single(c.ExitOnOOM, "-XX:+ExitOnOutOfMemoryError")
+		},
+	},
+	{ // FlightRecord and FlightRecordFile
+		value: func(opt string) (string, bool) {
+			if opt == "-XX:StartFlightRecording" {
+				return "", true
+			}
+			v, ok := cutPrefix(opt, "-XX:StartFlightRecording=filename=")
+			return v, ok && v != "" && !strings.Contains(v, ",")
+		},
+		set: func(c *Cmd, v string) {
+			c.FlightRecord = true
+			c.FlightRecordFile = v
+		},
+		render: func(c *Cmd) [][]string {
+			if !c.FlightRecord && c.FlightRecordFile == "" {
+				return nil
+			}
+			opt := "-XX:StartFlightRecording"
+			if c.FlightRecordFile != "" {
+				opt += "=filename=" + c.FlightRecordFile
+			}
+			return [][]string{{opt}}
+		},
+	},
+	{ // ErrorFile
+		value: prefixValue("-XX:ErrorFile="),
+		set:   func(c *Cmd, v string) { c.ErrorFile = v },
+		render: func(c *Cmd) [][]string {
+			return single(c.ErrorFile != "", "-XX:ErrorFile="+c.ErrorFile)
+		},
+	},
+	{ // HeapDumpPath
+		value: prefixValue("-XX:HeapDumpPath="),
+		set:   func(c *Cmd, v string) { c.HeapDumpPath = v },
+		render: func(c *Cmd) [][]string {
+			if c.HeapDumpPath == "" {
+				return nil
+			}
+			var opts []string
+			if !hasOption(c.Options, "-XX:+HeapDumpOnOutOfMemoryError") {
+				opts = append(opts, "-XX:+HeapDumpOnOutOfMemoryError")
+			}
+			return [][]string{append(opts, "-XX:HeapDumpPath="+c.HeapDumpPath)}
+		},
+	},
+	{ // Agents
+		value: func(opt string) (string, bool) { return cutPrefix(opt, "-javaagent:") },
+		set:   func(c *Cmd, v string) { c.Agents = append(c.Agents, v) },
+		render: func(c *Cmd) [][]string {
+			var opts [][]string
+			for _, it := range c.Agents {
+				jar, agentOpts, hasOpts := strings.Cut(it, "=")
+				jar = cachedIfRel(jar)
+				if hasOpts {
+					jar += "=" + agentOpts
+				}
+				opts = append(opts, []string{"-javaagent:" + jar})
+			}
+			return opts
+		},
+		repeat: true,
+	},
+	{ // LibraryPath
+		value: prefixValue("-Djava.library.path="),
+		set:   func(c *Cmd, v string) { c.LibraryPath = SplitClasspath(v) },
+		render: func(c *Cmd) [][]string {
+			if len(c.LibraryPath) == 0 {
+				return nil
+			}
+			paths := make([]string, len(c.LibraryPath))
+			for i, it := range c.LibraryPath {
+				paths[i] = cachedIfRel(it)
+			}
+			return [][]string{{"-Djava.library.path=" + JoinClasspath(paths)}}
+		},
+	},
+	{ // SharedArchive
+		value: prefixValue("-XX:SharedArchiveFile="),
+		set:   func(c *Cmd, v string) { c.SharedArchive = v },
+		render: func(c *Cmd) [][]string {
+			if c.SharedArchive == "" {
+				return nil
+			}
+			opts := []string{"-XX:SharedArchiveFile=" + cachedIfRel(c.SharedArchive)}
+			if !hasOptionPrefix(c.Options, "-Xshare:") {
+				opts = append(opts, "-Xshare:on")
+			}
+			return [][]string{opts}
+		},
+	},
+	{ // Splash
+		value: prefixValue("-splash:"),
+		set:   func(c *Cmd, v string) { c.Splash = v },
+		render: func(c *Cmd) [][]string {
+			return single(c.Splash != "", "-splash:"+cachedIfRel(c.Splash))
+		},
+	},
+	{ // FileEncoding
+		value: prefixValue("-Dfile.encoding="),
+		set:   func(c *Cmd, v string) { c.FileEncoding = v },
+		render: func(c *Cmd) [][]string {
+			return single(c.FileEncoding != "", "-Dfile.encoding="+c.FileEncoding)
+		},
+	},
+	{ // BaseDir
+		value: prefixValue("-Dbasedir="),
+		set:   func(c *Cmd, v string) { c.BaseDir = v },
+		render: func(c *Cmd) [][]string {
+			return single(c.BaseDir != "", "-Dbasedir="+c.BaseDir)
+		},
+	},
+	{ // Properties
+		render: func(c *Cmd) [][]string {
+			if props := c.properties(); len(props) > 0 {
+				return [][]string{props}
+			}
+			return nil
+		},
+	},
+	{ // MaxHeap
+		value: prefixValue("-Xmx"),
+		set:   func(c *Cmd, v string) { c.MaxHeap = v },
+		render: func(c *Cmd) [][]string {
+			return single(c.MaxHeap != "", "-Xmx"+c.MaxHeap)
+		},
+	},
+	{ // MinHeap
+		value: prefixValue("-Xms"),
+		set:   func(c *Cmd, v string) { c.MinHeap = v },
+		render: func(c *Cmd) [][]string {
+			return single(c.MinHeap != "", "-Xms"+c.MinHeap)
+		},
+	},
+	{ // MaxRAMPercentage
+		value: func(opt string) (string, bool) {
+			v, ok := cutPrefix(opt, "-XX:MaxRAMPercentage=")
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return v, false
+			}
+			return v, ok
+		},
+		set: func(c *Cmd, v string) { c.MaxRAMPercentage, _ = strconv.ParseFloat(v, 64) },
+		render: func(c *Cmd) [][]string {
+			return single(c.MaxRAMPercentage != 0, "-XX:MaxRAMPercentage="+
+				strconv.FormatFloat(c.MaxRAMPercentage, 'f', -1, 64))
+		},
+	},
+	{ // GCThreads
+		value: func(opt string) (string, bool) {
+			v, ok := cutPrefix(opt, "-XX:ParallelGCThreads=")
+			if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+				return v, false
+			}
+			return v, ok
+		},
+		set: func(c *Cmd, v string) { c.GCThreads, _ = strconv.Atoi(v) },
+		render: func(c *Cmd) [][]string {
+			return single(c.GCThreads != 0, "-XX:ParallelGCThreads="+strconv.Itoa(c.GCThreads))
+		},
+	},
+	{ // UseG1
+		value: flagValue("UseG1GC"),
+		set:   func(c *Cmd, v string) { c.UseG1 = v == "true" },
+		render: func(c *Cmd) [][]string {
+			return single(c.UseG1, "-XX:+UseG1GC")
+		},
+	},
+	{ // UseZGC
+		value: flagValue("UseZGC"),
+		set:   func(c *Cmd, v string) { c.UseZGC = v == "true" },
+		render: func(c *Cmd) [][]string {
+			return single(c.UseZGC, "-XX:+UseZGC")
+		},
+	},
+	{ // AddOpens
+		value: prefixValue("--add-opens="),
+		set:   func(c *Cmd, v string) { c.AddOpens = append(c.AddOpens, v) },
+		render: func(c *Cmd) [][]string {
+			return pairs("--add-opens", c.AddOpens)
+		},
+		repeat: true,
+	},
+	{ // AddExports
+		value: prefixValue("--add-exports="),
+		set:   func(c *Cmd, v string) { c.AddExports = append(c.AddExports, v) },
+		render: func(c *Cmd) [][]string {
+			return pairs("--add-exports", c.AddExports)
+		},
+		repeat: true,
+	},
+}
+
+// single returns opt as the only option rendered for a field if set.
+func single(set bool, opt string) [][]string {
+	if !set {
+		return nil
+	}
+	return [][]string{{opt}}
+}
+
+// pairs returns the option followed by each of the values.
+func pairs(opt string, values []string) [][]string {
+	var opts [][]string
+	for _, it := range values {
+		opts = append(opts, []string{opt, it})
+	}
+	return opts
+}
+
+// prefixValue returns a typedOption value function for options
+// beginning with prefix and followed by a value that is not empty.
+func prefixValue(prefix string) func(string) (string, bool) {
+	return func(opt string) (string, bool) {
+		v, ok := cutPrefix(opt, prefix)
+		return v, ok && v != ""
+	}
+}
+
+// flagValue returns a typedOption value function for the boolean -XX
+// flag with the given name with a value of "true" for -XX:+name and
+// "false" for -XX:-name.
+func flagValue(name string) func(string) (string, bool) {
+	return func(opt string) (string, bool) {
+		switch opt {
+		case "-XX:+" + name:
+			return "true", true
+		case "-XX:-" + name:
+			return "false", true
+		}
+		return "", false
+	}
+}
+
+// cachedIfRel returns the Cached path for the relative path (if
+// cached) or path unchanged.
+func cachedIfRel(path string) string {
+	if !filepath.IsAbs(path) {
+		if cached := Cached(path); cached != "" {
+			return cached
+		}
+	}
+	return path
+}
+
+// typedIndex returns the index within typedOptions of the field that
+// opt (joined with its value for ValueOptions, --add-opens=foo)
+// corresponds to or -1 if none.
+func typedIndex(opt string) int {
+	for i, t := range typedOptions {
+		if t.value == nil {
+			continue
+		}
+		if _, ok := t.value(opt); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// options returns the Options with the typed fields rendered among
+// them. Any typed field that is set takes the place of the last option
+// in Options of the same kind (or of each, in order, for repeat fields
+// such as Agents with any others dropped and any extra added after the
+// last). This keeps the original order of options parsed by ParseCmd
+// (which matters for -XX flags that override one another) while any
+// change to a typed field still takes effect. Typed fields with no
+// option of the same kind in Options are rendered before all Options.
+// Any -Djava.security.manager option is dropped just as it is from
+// Properties (see securityManagerOK).
+func (c *Cmd) options() []string {
+	type slot struct{ at, n int }
+	var slots []slot
+	found := make([][]int, len(typedOptions))
+	for i := 0; i < len(c.Options); i++ {
+		opt, n := c.Options[i], 1
+		if isValueOption(opt) && i+1 < len(c.Options) {
+			opt, n = opt+"="+c.Options[i+1], 2
+		}
+		kind := typedIndex(opt)
+		if kind >= 0 {
+			found[kind] = append(found[kind], len(slots))
+		}
+		slots = append(slots, slot{i, n})
+		i += n - 1
+	}
+
+	var opts []string
+	instead := map[int][]string{}
+	for kind, t := range typedOptions {
+		units := t.render(c)
+		at := found[kind]
+		switch {
+		case len(units) == 0:
+			continue
+		case len(at) == 0:
+			for _, it := range units {
+				opts = append(opts, it...)
+			}
+			continue
+		case !t.repeat:
+			at = at[len(at)-1:]
+		}
+		for n, it := range at {
+			var r []string
+			if n < len(units) {
+				r = units[n]
+			}
+			for j := n + 1; n == len(at)-1 && j < len(units); j++ {
+				r = append(r, units[j]...)
+			}
+			instead[it] = append([]string{}, r...)
+		}
+	}
+
+	for i, it := range slots {
+		if r, ok := instead[i]; ok {
+			opts = append(opts, r...)
+			continue
+		}
+		const sm = "-Djava.security.manager="
+		if opt := c.Options[it.at]; strings.HasPrefix(opt, sm) &&
+			!c.securityManagerOK(strings.TrimPrefix(opt, sm)) {
+			continue
+		}
+		opts = append(opts, c.Options[it.at:it.at+it.n]...)
+	}
+	if c.Module != "" {
+		opts = addModulePath(opts)
+	}
+	return opts
+}
+
+// properties returns the Properties as -D options sorted by key
+// dropping any java.security.manager property that the java (see
+// Executable) can no longer accept.
+func (c *Cmd) properties() []string {
+	keys := make([]string, 0, len(c.Properties))
+	for k := range c.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var opts []string
+	for _, k := range keys {
+		v := c.Properties[k]
+		if k == "java.security.manager" && !c.securityManagerOK(v) {
+			continue
+		}
+		opts = append(opts, "-D"+k+"="+v)
+	}
+	return opts
+}
+
+// securityManagerOK returns false (after logging a warning) if the
+// java.security.manager value would enable or allow the Security
+// Manager on JDK 24 or later where it has been removed (JEP 486). JDK
+// 18 through 23 accept it with a deprecation warning of their own. If
+// the version cannot be determined the property is kept. The version
+// of each executable is only checked (see cachedVersion) and warned
+// about once.
+func (c *Cmd) securityManagerOK(val string) bool {
+	if val == "disallow" {
+		return true
+	}
+	exe := c.Executable
+	if exe == "" {
+		exe = "java"
+	}
+	ver, err := cachedVersion(exe)
+	if err != nil || ver < 24 {
+		return true
+	}
+	if _, warned := securityWarned.LoadOrStore(exe, true); !warned {
+		Logger.Printf(
+			"java: ignoring java.security.manager=%v (Security Manager removed in JDK 24, found %v)",
+			val, ver)
+	}
+	return false
+}
+
+// securityWarned holds each executable already warned about by
+// securityManagerOK.
+var securityWarned sync.Map
+
+// ModulePath returns the module path that is added for every Cmd with
+// a Module: the CacheDir followed by the SystemCacheDir (if set) so
+// that extracted modular jars and jmods can be found.
+func ModulePath() string {
+	if SystemCacheDir == "" {
+		return CacheDir
+	}
+	return CacheDir + string(os.PathListSeparator) + SystemCacheDir
+}
+
+// addModulePath returns a copy of opts with the ModulePath added to the
+// end of the first --module-path (or -p) option or as a new option if
+// there is none.
+func addModulePath(opts []string) []string {
+	opts = append([]string(nil), opts...)
+	sep := string(os.PathListSeparator)
+	for i, it := range opts {
+		switch {
+		case (it == "-p" || it == "--module-path") && i+1 < len(opts):
+			opts[i+1] += sep + ModulePath()
+			return opts
+		case strings.HasPrefix(it, "--module-path="):
+			opts[i] += sep + ModulePath()
+			return opts
+		}
+	}
+	return append(opts, "--module-path", ModulePath())
+}
+
+// parseOption assigns the value of the option (joined with its value
+// for ValueOptions, --add-opens=foo) to its corresponding typed field
+// and returns true, or returns false if the option has no typed field
+// that is parsed (or its value is invalid).
+func (c *Cmd) parseOption(opt string) bool {
+	kind := typedIndex(opt)
+	if kind < 0 || typedOptions[kind].set == nil {
+		return false
+	}
+	v, _ := typedOptions[kind].value(opt)
+	typedOptions[kind].set(c, v)
+	return true
+}
+
+// DedupeOptions removes redundant Options while keeping their effect
+// the same. Of any -D system properties with the same name only the
+// last remains (since the last wins) and the remaining properties are
+// sorted by name among the positions they occupy. Any other option
+// (along with its value for ValueOptions) that is repeated exactly
+// keeps only its last occurrence. Everything else is left in its
+// original order since order matters for -XX options (where a later
+// -XX:-Foo overrides an earlier -XX:+Foo), -ea/-da assertion options,
+// agents (which load in order), and the module options.
+func (c *Cmd) DedupeOptions() {
+	type unit struct {
+		key  string
+		opts []string
+	}
+	var units []unit
+	for i := 0; i < len(c.Options); i++ {
+		u := unit{opts: []string{c.Options[i]}}
+		if isValueOption(c.Options[i]) && i+1 < len(c.Options) {
+			i++
+			u.opts = append(u.opts, c.Options[i])
+		}
+		u.key = strings.Join(u.opts, " ")
+		if strings.HasPrefix(u.key, "-D") {
+			u.key, _, _ = strings.Cut(u.key, "=")
+		}
+		units = append(units, u)
+	}
+
+	seen := map[string]bool{}
+	kept := make([]unit, 0, len(units))
+	for i := len(units) - 1; i >= 0; i-- {
+		if seen[units[i].key] {
+			continue
+		}
+		seen[units[i].key] = true
+		kept = append([]unit{units[i]}, kept...)
+	}
+
+	var slots []int
+	var props []string
+	for i, u := range kept {
+		if strings.HasPrefix(u.key, "-D") {
+			slots = append(slots, i)
+			props = append(props, u.opts[0])
+		}
+	}
+	sort.Strings(props)
+	for i, slot := range slots {
+		kept[slot].opts = []string{props[i]}
+	}
+
+	var opts []string
+	for _, u := range kept {
+		opts = append(opts, u.opts...)
+	}
+	c.Options = opts
+}
+
+// hasOption returns true if opts contains opt.
+func hasOption(opts []string, opt string) bool {
+	for _, it := range opts {
+		if it == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOptionPrefix returns true if any of opts begins with prefix.
+func hasOptionPrefix(opts []string, prefix string) bool {
+	for _, it := range opts {
+		if strings.HasPrefix(it, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cutPrefix returns opt without prefix and true if it had the prefix.
+func cutPrefix(opt, prefix string) (string, bool) {
+	if !strings.HasPrefix(opt, prefix) {
+		return opt, false
+	}
+	return opt[len(prefix):], true
+}
+
+// LoadOptions reads java options from the file at path (one per line)
+// and returns them ready to be prepended to the Options of a Cmd. Blank
+// lines and lines beginning with # are ignored. Options in ValueOptions
+// may have their value on the same line (-cp lib/foo.jar). An error is
+// returned for anything that ParseCmd would not accept as an option.
+func LoadOptions(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var opts []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		opts = append(opts, strings.Fields(line)...)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	c := ParseCmd(opts...)
+	if c.Name != "" {
+		return nil, fmt.Errorf("%v: not an option: %v", path, c.Name)
+	}
+	if len(c.Unrecognized) > 0 {
+		return nil, fmt.Errorf("%v: unrecognized options: %v", path, c.Unrecognized)
+	}
+	return opts, nil
+}
+
+// collectorOptions are the options that each select a garbage
+// collector (only one of which may be used).
+var collectorOptions = []string{
+	"-XX:+UseSerialGC", "-XX:+UseParallelGC", "-XX:+UseG1GC",
+	"-XX:+UseZGC", "-XX:+UseShenandoahGC", "-XX:+UseEpsilonGC",
+}
+
+// collectors returns the distinct garbage collector options that will
+// be rendered for the Cmd.
+func (c *Cmd) collectors() []string {
+	var gcs []string
+	opts := c.options()
+	for _, it := range collectorOptions {
+		if hasOption(opts, it) {
+			gcs = append(gcs, it)
+		}
+	}
+	return gcs
+}
+
+var (
+	shortOption = regexp.MustCompile(`^-[A-Za-z?][^=]*(?:[=:].*)?$`)
+	longOption  = regexp.MustCompile(`^--[A-Za-z][A-Za-z0-9@.-]*(?:=.*)?$`)
+)
+
+// Validate returns an error if any of the Options are not acceptable
+// java options or if ParseCmd found any Unrecognized ones. Options must
+// be either single dash (-Dfoo=bar, -XX:+UseG1GC, -ea:com.foo...) or
+// double dash long options with or without an equals sign value
+// (--enable-preview, --finalization=disabled). The values following
+// any ValueOptions (-cp foo.jar) are also allowed. An error is also
+// returned if more than one garbage collector is selected (UseG1,
+// UseZGC, or any of the -XX:+Use*GC collector Options).
+func (c *Cmd) Validate() error {
+	if len(c.Unrecognized) > 0 {
+		return fmt.Errorf("unrecognized options: %v", c.Unrecognized)
+	}
+	if gcs := c.collectors(); len(gcs) > 1 {
+		return fmt.Errorf("conflicting garbage collectors: %v", gcs)
+	}
+	for i := 0; i < len(c.Options); i++ {
+		it := c.Options[i]
+		switch {
+		case longOption.MatchString(it), shortOption.MatchString(it):
+			if isValueOption(it) {
+				i++
+			}
+		default:
+			return fmt.Errorf("invalid option: %q", it)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package java_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleLoadOptions() {
+
+	opts, err := java.LoadOptions("testdata/jvm.options")
+	fmt.Println(opts, err)
+
+	_, err = java.LoadOptions("testdata/bad.options")
+	fmt.Println(err)
+
+	// Output:
+	// [-XX:MaxRAMPercentage=50 -Dfoo=bar -cp lib/foo.jar] <nil>
+	// testdata/bad.options: not an option: Main
+}
+
+func ExampleParseCmd_module() {
+
+	java.CacheDir = "testdata/tmpcache"
+
+	c := java.ParseCmd("-p", "mods", "-m", "com.foo/com.foo.Main", "arg")
+	fmt.Println(c.Module)
+	fmt.Println(c.Args)
+	fmt.Println(c.Argv())
+
+	c = java.ParseCmd("--module=com.foo", "arg")
+	fmt.Println(c.Argv())
+
+	// Output:
+	// com.foo/com.foo.Main
+	// [arg]
+	// [java -p mods:testdata/tmpcache -m com.foo/com.foo.Main arg]
+	// [java --module-path testdata/tmpcache -m com.foo arg]
+}
+
+func ExampleParseCmd_fileEncoding() {
+
+	c := java.ParseCmd("-Dfile.encoding=UTF-8", "Main")
+	fmt.Println(c.FileEncoding)
+	fmt.Println(c.Argv())
+
+	c = java.ParseCmd("-Dfile.encoding=", "Main")
+	fmt.Printf("%q %v\n", c.FileEncoding, c.Argv())
+
+	// Output:
+	// UTF-8
+	// [java -Dfile.encoding=UTF-8 Main]
+	// "" [java -Dfile.encoding= Main]
+}
+
+func ExampleParseCmd_baseDir() {
+
+	java.CacheDir = "testdata/tmpcache"
+
+	c := java.ParseCmd("-Dbasedir=/opt/tool", "Main")
+	fmt.Println(c.BaseDir, c.Options)
+
+	c = &java.Cmd{Name: "Main", BaseDir: java.CacheDir}
+	fmt.Println(c.Argv())
+
+	// Output:
+	// /opt/tool [-Dbasedir=/opt/tool]
+	// [java -Dbasedir=testdata/tmpcache Main]
+}
+
+func ExampleParseCmd_flightRecord() {
+
+	c := java.ParseCmd(
+		"-XX:+FlightRecorder", "-XX:StartFlightRecording=filename=app.jfr", "Main",
+	)
+	fmt.Println(c.FlightRecord, c.FlightRecordFile, c.Options)
+	fmt.Println(c.Argv())
+
+	c = java.ParseCmd("-XX:StartFlightRecording=duration=30s,filename=a.jfr", "Main")
+	fmt.Println(c.FlightRecord, c.Options)
+
+	c = &java.Cmd{Name: "Main", FlightRecord: true}
+	fmt.Println(c.Argv())
+
+	// Output:
+	// true app.jfr [-XX:+FlightRecorder -XX:StartFlightRecording=filename=app.jfr]
+	// [java -XX:+FlightRecorder -XX:StartFlightRecording=filename=app.jfr Main]
+	// false [-XX:StartFlightRecording=duration=30s,filename=a.jfr]
+	// [java -XX:StartFlightRecording Main]
+}
+
+func ExampleParseCmd_exitOnOOM() {
+
+	c := java.ParseCmd("-XX:+ExitOnOutOfMemoryError", "Server")
+	fmt.Println(c.ExitOnOOM, c.Options)
+	fmt.Println(c.Argv())
+
+	for _, opts := range [][]string{
+		{"-XX:-ExitOnOutOfMemoryError", "-XX:+ExitOnOutOfMemoryError"},
+		{"-XX:+ExitOnOutOfMemoryError", "-XX:-ExitOnOutOfMemoryError"},
+	} {
+		c = java.ParseCmd(append(opts, "Main")...)
+		fmt.Println(c.ExitOnOOM, c.Argv())
+	}
+
+	// Output:
+	// true [-XX:+ExitOnOutOfMemoryError]
+	// [java -XX:+ExitOnOutOfMemoryError Server]
+	// true [java -XX:-ExitOnOutOfMemoryError -XX:+ExitOnOutOfMemoryError Main]
+	// false [java -XX:+ExitOnOutOfMemoryError -XX:-ExitOnOutOfMemoryError Main]
+}
+
+func ExampleCmd_Validate() {
+
+	for _, opts := range [][]string{
+		{"--finalization=disabled", "--enable-preview", "--illegal-access=deny"},
+		{"--enable-native-access=ALL-UNNAMED", "--add-opens", "java.base/java.lang=ALL-UNNAMED"},
+		{"-XX:+UseZGC", "-Dfoo=bar", "-ea:com.foo...", "-Xss1m", "-verbose:gc"},
+		{"--show-version", "--sun-misc-unsafe-memory-access=allow"},
+		{"--"},
+		{"---bad"},
+		{"--=bad"},
+	} {
+		c := &java.Cmd{Name: "Main", Options: opts}
+		fmt.Println(c.Validate())
+	}
+
+	// Output:
+	// <nil>
+	// <nil>
+	// <nil>
+	// <nil>
+	// invalid option: "--"
+	// invalid option: "---bad"
+	// invalid option: "--=bad"
+}
+
+func ExampleParseCmd_agents() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	os.MkdirAll("testdata/tmpcache", 0700)
+	os.WriteFile("testdata/tmpcache/prof.jar", nil, 0600)
+
+	c := java.ParseCmd(
+		"-javaagent:prof.jar=interval=10", "-Dx=y", "-javaagent:/opt/other.jar",
+		"Main",
+	)
+	fmt.Println(c.Agents)
+	fmt.Println(c.Argv())
+
+	// Output:
+	// [prof.jar=interval=10 /opt/other.jar]
+	// [java -javaagent:testdata/tmpcache/prof.jar=interval=10 -Dx=y -javaagent:/opt/other.jar Main]
+}
+
+func ExampleCmd_Properties() {
+
+	java.Logger.SetOutput(os.Stdout)
+	java.Logger.SetFlags(0)
+	defer func() {
+		java.Logger.SetOutput(os.Stderr)
+		java.Logger.SetFlags(log.LstdFlags)
+	}()
+
+	c := &java.Cmd{
+		Name:       "Main",
+		Executable: "testdata/jdk8/bin/java",
+		Properties: map[string]string{
+			"java.security.manager": "allow",
+			"foo":                   "bar",
+		},
+	}
+	fmt.Println(c.Argv())
+
+	c.Executable = "testdata/jdk24/bin/java"
+	fmt.Println(c.Argv())
+	fmt.Println(c.Argv())
+
+	c = java.ParseCmd("-Djava.security.manager=allow", "-Dx=y", "Main")
+	c.Executable = "testdata/jdk24/bin/java"
+	fmt.Println(c.Argv())
+	c.Options[0] = "-Djava.security.manager=disallow"
+	fmt.Println(c.Argv())
+
+	// Output:
+	// [testdata/jdk8/bin/java -Dfoo=bar -Djava.security.manager=allow Main]
+	// java: ignoring java.security.manager=allow (Security Manager removed in JDK 24, found 24)
+	// [testdata/jdk24/bin/java -Dfoo=bar Main]
+	// [testdata/jdk24/bin/java -Dfoo=bar Main]
+	// [testdata/jdk24/bin/java -Dx=y Main]
+	// [testdata/jdk24/bin/java -Djava.security.manager=disallow -Dx=y Main]
+}
+
+func ExampleParseCmd_heapDumpPath() {
+
+	c := java.ParseCmd(
+		"-XX:+HeapDumpOnOutOfMemoryError", "-XX:HeapDumpPath=/var/dumps",
+		"-XX:ErrorFile=/var/log/hs_err_%p.log", "Main",
+	)
+	fmt.Println(c.HeapDumpPath, c.ErrorFile, c.Options)
+	fmt.Println(c.Argv())
+
+	c = &java.Cmd{Name: "Main", HeapDumpPath: "dumps"}
+	fmt.Println(c.Argv())
+
+	// Output:
+	// /var/dumps /var/log/hs_err_%p.log [-XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=/var/dumps -XX:ErrorFile=/var/log/hs_err_%p.log]
+	// [java -XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=/var/dumps -XX:ErrorFile=/var/log/hs_err_%p.log Main]
+	// [java -XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=dumps Main]
+}
+
+func ExampleParseCmd_gc() {
+
+	c := java.ParseCmd("-XX:ParallelGCThreads=4", "-XX:+UseZGC", "Batch")
+	fmt.Println(c.GCThreads, c.UseZGC, c.Options)
+	fmt.Println(c.Argv())
+	fmt.Println(c.Validate())
+
+	c.UseG1 = true
+	fmt.Println(c.Validate())
+
+	c = &java.Cmd{Name: "Batch", UseG1: true, Options: []string{"-XX:+UseParallelGC"}}
+	fmt.Println(c.Validate())
+
+	c = java.ParseCmd("-XX:-UseG1GC", "-XX:+UseG1GC", "Main")
+	fmt.Println(c.UseG1, c.Argv())
+
+	c = java.ParseCmd("-XX:+UseG1GC", "-XX:-UseG1GC", "Main")
+	fmt.Println(c.UseG1, c.Argv())
+
+	c = java.ParseCmd("-XX:+UseG1GC", "-Dx=y", "Main")
+	c.UseG1 = false
+	fmt.Println(c.Argv())
+	c.Options = c.Options[1:]
+	fmt.Println(c.Argv())
+
+	// Output:
+	// 4 true [-XX:ParallelGCThreads=4 -XX:+UseZGC]
+	// [java -XX:ParallelGCThreads=4 -XX:+UseZGC Batch]
+	// <nil>
+	// conflicting garbage collectors: [-XX:+UseG1GC -XX:+UseZGC]
+	// conflicting garbage collectors: [-XX:+UseParallelGC -XX:+UseG1GC]
+	// true [java -XX:-UseG1GC -XX:+UseG1GC Main]
+	// false [java -XX:+UseG1GC -XX:-UseG1GC Main]
+	// [java -XX:+UseG1GC -Dx=y Main]
+	// [java -Dx=y Main]
+}
+
+func ExampleCmd_DedupeOptions() {
+
+	c := java.ParseCmd(
+		"-Dz=1", "-XX:+UseStringDeduplication", "-cp", "a.jar", "-Da=1",
+		"-XX:-UseStringDeduplication", "-Dz=2", "--enable-preview",
+		"-XX:+UseStringDeduplication", "-cp", "a.jar", "--enable-preview",
+		"Main",
+	)
+	c.DedupeOptions()
+	fmt.Println(c.Options)
+
+	// Output:
+	// [-Da=1 -XX:-UseStringDeduplication -Dz=2 -XX:+UseStringDeduplication -cp a.jar --enable-preview]
+}
+
+func ExampleParseCmd_addOpens() {
+
+	c := java.ParseCmd(
+		"--add-opens", "java.base/java.lang=ALL-UNNAMED",
+		"--add-exports=java.base/sun.nio.ch=ALL-UNNAMED",
+		"--add-opens=java.base/java.util=ALL-UNNAMED",
+		"Main",
+	)
+	fmt.Println(c.AddOpens)
+	fmt.Println(c.AddExports)
+	fmt.Println(c.Options)
+	fmt.Println(c.Argv())
+
+	// Output:
+	// [java.base/java.lang=ALL-UNNAMED java.base/java.util=ALL-UNNAMED]
+	// [java.base/sun.nio.ch=ALL-UNNAMED]
+	// [--add-opens java.base/java.lang=ALL-UNNAMED --add-exports=java.base/sun.nio.ch=ALL-UNNAMED --add-opens=java.base/java.util=ALL-UNNAMED]
+	// [java --add-opens java.base/java.lang=ALL-UNNAMED --add-exports java.base/sun.nio.ch=ALL-UNNAMED --add-opens java.base/java.util=ALL-UNNAMED Main]
+}
+
+func ExampleParseCmd_splash() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	os.MkdirAll("testdata/tmpcache/img", 0700)
+	os.WriteFile("testdata/tmpcache/img/splash.png", nil, 0600)
+
+	c := java.ParseCmd("-splash:img/splash.png", "-jar", "app.jar")
+	fmt.Println(c.Splash)
+	fmt.Println(c.Argv())
+
+	c.Splash = "other.png"
+	fmt.Println(c.Argv())
+
+	// Output:
+	// img/splash.png
+	// [java -splash:testdata/tmpcache/img/splash.png -jar app.jar]
+	// [java -splash:other.png -jar app.jar]
+}
+
+func ExampleParseCmd_libraryPath() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	os.MkdirAll("testdata/tmpcache/native", 0700)
+
+	c := java.ParseCmd("-Djava.library.path=native:/usr/lib/jni", "Main")
+	fmt.Println(c.LibraryPath, c.Options)
+	fmt.Println(c.Argv())
+
+	c = java.ParseCmd("-Djava.library.path=a", "-Djava.library.path=b", "Main")
+	fmt.Println(c.LibraryPath, c.Argv())
+
+	c = java.ParseCmd("-Djava.library.path=", "Main")
+	fmt.Println(c.LibraryPath, c.Argv())
+
+	// Output:
+	// [native /usr/lib/jni] [-Djava.library.path=native:/usr/lib/jni]
+	// [java -Djava.library.path=testdata/tmpcache/native:/usr/lib/jni Main]
+	// [b] [java -Djava.library.path=a -Djava.library.path=b Main]
+	// [] [java -Djava.library.path= Main]
+}
+
+func ExampleParseCmd_sharedArchive() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.RemoveAll("testdata/tmpcache")
+	os.MkdirAll("testdata/tmpcache", 0700)
+	os.WriteFile("testdata/tmpcache/app.jsa", nil, 0600)
+
+	c := java.ParseCmd("-XX:SharedArchiveFile=app.jsa", "Main")
+	fmt.Println(c.SharedArchive, c.Options)
+	fmt.Println(c.Argv())
+
+	c = java.ParseCmd("-XX:SharedArchiveFile=/opt/app.jsa", "-Xshare:auto", "Main")
+	fmt.Println(c.Argv())
+
+	// Output:
+	// app.jsa [-XX:SharedArchiveFile=app.jsa]
+	// [java -XX:SharedArchiveFile=testdata/tmpcache/app.jsa -Xshare:on Main]
+	// [java -XX:SharedArchiveFile=/opt/app.jsa -Xshare:auto Main]
+}
@@ -0,0 +1,67 @@
+package java
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrOutputTooLarge is returned (along with the output truncated to the
+// limit) when captured output exceeds MaxOutputBytes.
+var ErrOutputTooLarge = errors.New("java output too large (see MaxOutputBytes)")
+
+// MaxOutputBytes (when non-zero) is the most standard output the
+// capturing functions (Out, OutCP, OutContext, OutLines, OutTee, Pipe,
+// Run, and Cmd.Output) keep in memory. Once exceeded the java process is killed
+// and ErrOutputTooLarge returned (or logged by Out and OutCP) with the
+// output so far truncated to the limit. This protects long-running
+// servers from embedded programs that run away. See Cmd.MaxOutputBytes
+// to set it for a single Cmd.
+var MaxOutputBytes int
+
+// maxOutput returns the MaxOutputBytes of the Cmd if set or the package
+// MaxOutputBytes otherwise.
+func (c *Cmd) maxOutput() int {
+	if c.MaxOutputBytes != 0 {
+		return c.MaxOutputBytes
+	}
+	return MaxOutputBytes
+}
+
+// capBuffer is a buffer that keeps no more than max bytes (when max is
+// non-zero) and calls kill the first time more is written. Any more is
+// discarded (rather than failing the write) so that the java process
+// never blocks on a full pipe before it is killed. (The bytes.Buffer is
+// not embedded since its ReadFrom would be used by io.Copy instead of
+// Write.)
+type capBuffer struct {
+	buf  bytes.Buffer
+	max  int
+	kill func()
+	over bool
+}
+
+func (b *capBuffer) Write(p []byte) (int, error) {
+	if b.max <= 0 {
+		return b.buf.Write(p)
+	}
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:room])
+		if !b.over {
+			b.over = true
+			b.kill()
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *capBuffer) String() string { return b.buf.String() }
+
+// tooLarge returns ErrOutputTooLarge if the limit was exceeded or err
+// unchanged.
+func (b *capBuffer) tooLarge(err error) error {
+	if b.over {
+		return ErrOutputTooLarge
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package java_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleMaxOutputBytes() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/loudjava")
+	os.Setenv("PATH", bin)
+
+	c := java.ParseCmd("Main")
+	c.MaxOutputBytes = 25
+	out, err := c.Output()
+	fmt.Printf("%q %v\n", out, err)
+
+	java.MaxOutputBytes = 5
+	defer func() { java.MaxOutputBytes = 0 }()
+	out, _, _, err = java.Run("Main")
+	fmt.Printf("%q %v\n", out, err)
+
+	out, err = java.OutTee(io.Discard, "Main")
+	fmt.Printf("%q %v\n", out, err)
+
+	out, err = java.Pipe([]string{"Main"}, []string{"Main"})
+	fmt.Printf("%q %v\n", out, err)
+
+	// Output:
+	// "0123456789\n0123456789\n012" java output too large (see MaxOutputBytes)
+	// "01234" java output too large (see MaxOutputBytes)
+	// "01234" java output too large (see MaxOutputBytes)
+	// "01234" java output too large (see MaxOutputBytes)
+}
@@ -0,0 +1,78 @@
+package java
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Pipe runs each of the java commands (see Exec) at the same time with
+// the standard output of each connected to the standard input of the
+// next (like a shell pipeline) and returns the standard output of the
+// last. The first reads from os.Stdin and all write their standard
+// error to os.Stderr. Every command is waited for and the error of the
+// first that failed (if any) is returned identifying its stage (from
+// 1) along with whatever output was produced (an *ExecError for a
+// non-zero exit). All are killed if the
+// output of the last exceeds MaxOutputBytes.
+func Pipe(cmds ...[]string) (string, error) {
+	if len(cmds) == 0 {
+		return "", errors.New("nothing to pipe")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stages := make([]*exec.Cmd, len(cmds))
+	for i, it := range cmds {
+		c, err := ParseCmd(it...).command(ctx, nil)
+		if err != nil {
+			return "", fmt.Errorf("stage %v: %w", i+1, err)
+		}
+		stages[i] = c
+	}
+
+	var closers []*os.File
+	for i := 0; i < len(stages)-1; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeAll(closers)
+			return "", err
+		}
+		stages[i].Stdout, stages[i+1].Stdin = w, r
+		closers = append(closers, r, w)
+	}
+	out := &capBuffer{max: MaxOutputBytes, kill: cancel}
+	stages[len(stages)-1].Stdout = out
+	tails := make([]*tailBuffer, len(stages))
+	for i, c := range stages {
+		tails[i] = captureStderr(c)
+	}
+
+	for i, c := range stages {
+		if err := c.Start(); err != nil {
+			for _, started := range stages[:i] {
+				started.Process.Kill()
+				started.Wait()
+			}
+			closeAll(closers)
+			return "", fmt.Errorf("stage %v: %w", i+1, err)
+		}
+	}
+	closeAll(closers) // only the java processes hold them now
+
+	var first error
+	for i, c := range stages {
+		if err := c.Wait(); err != nil && first == nil {
+			first = fmt.Errorf("stage %v: %w", i+1, execError(c, tails[i].String(), err))
+		}
+	}
+	return out.String(), out.tooLarge(first)
+}
+
+// closeAll closes all the files ignoring any errors.
+func closeAll(files []*os.File) {
+	for _, it := range files {
+		it.Close()
+	}
+}
@@ -0,0 +1,33 @@
+package java_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/java"
+)
+
+func ExamplePipe() {
+
+	out, err := java.Pipe(
+		[]string{"-jar", "testdata/files.jar"},
+		[]string{"-jar", "testdata/files.jar"},
+	)
+	fmt.Print(out)
+	fmt.Println(err)
+
+	// Output:
+	// Hello, World!
+	// <nil>
+}
+
+func ExamplePipe_error() {
+
+	_, err := java.Pipe(
+		[]string{"-Dx=y"},
+		[]string{"-jar", "testdata/files.jar"},
+	)
+	fmt.Println(err)
+
+	// Output:
+	// stage 1: no main class, jar, or module given
+}
@@ -0,0 +1,53 @@
+package java
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// prefixWriter writes the prefix at the beginning of every line
+// written to w (see Cmd.LogPrefix). Each line (or partial line) is
+// written with a single call to w so that lines from several processes
+// sharing the same w are not broken up.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	mid    bool // within a line already prefixed
+}
+
+// prefixMu is held by every prefixWriter while writing since the
+// standard output and error of one or more processes (each with their
+// own prefixWriter) may share the same w (which need not be safe for
+// concurrent use, bytes.Buffer).
+var prefixMu sync.Mutex
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	prefixMu.Lock()
+	defer prefixMu.Unlock()
+	var buf bytes.Buffer
+	for rest := b; len(rest) > 0; {
+		if !p.mid {
+			buf.WriteString(p.prefix)
+		}
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			buf.Write(rest)
+			p.mid = true
+			break
+		}
+		buf.Write(rest[:i+1])
+		rest = rest[i+1:]
+		p.mid = false
+		if _, err := p.w.Write(buf.Bytes()); err != nil {
+			return 0, err
+		}
+		buf.Reset()
+	}
+	if buf.Len() > 0 {
+		if _, err := p.w.Write(buf.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
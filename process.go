@@ -0,0 +1,250 @@
+package java
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rwxrob/java/internal"
+)
+
+// Process is a started java process (see ExecAsync).
+type Process struct {
+	Cmd    *exec.Cmd
+	stderr *tailBuffer
+}
+
+// ExecAsync is the same as Exec but returns as soon as the java
+// process has been started (with all cache resolution already done)
+// rather than waiting for it to finish. Call Wait on the returned
+// Process to wait for it (and to release its resources).
+func ExecAsync(cmd ...string) (*Process, error) {
+	args, err := argv(cmd...)
+	if err != nil {
+		return nil, err
+	}
+	c, err := internal.Command(args...)
+	if err != nil {
+		return nil, err
+	}
+	stderr := captureStderr(c)
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	return &Process{Cmd: c, stderr: stderr}, nil
+}
+
+// Start is the same as ExecAsync but for the Cmd (observing its
+// Stdout, Stderr, LogPrefix, and so on but not its Timeout).
+func (c *Cmd) Start() (*Process, error) {
+	cmd, err := c.command(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	stderr := captureStderr(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &Process{Cmd: cmd, stderr: stderr}, nil
+}
+
+// Wait waits for the process to exit and returns any error (see
+// exec.Cmd.Wait) as an *ExecError for a non-zero exit.
+func (p *Process) Wait() error {
+	err := p.Cmd.Wait()
+	var stderr string
+	if p.stderr != nil {
+		stderr = p.stderr.String()
+	}
+	return execError(p.Cmd, stderr, err)
+}
+
+// Kill causes the process to exit immediately. It does not wait.
+func (p *Process) Kill() error { return p.Cmd.Process.Kill() }
+
+// Pid returns the process ID of the process.
+func (p *Process) Pid() int { return p.Cmd.Process.Pid }
+
+// TransientErrors are the errors for which ExecRetry retries starting
+// java. ETXTBSY (text file busy) is common immediately after an
+// executable has been written.
+var TransientErrors = []error{syscall.ETXTBSY, syscall.EAGAIN}
+
+func isTransient(err error) bool {
+	for _, it := range TransientErrors {
+		if errors.Is(err, it) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecRetry is the same as Exec but retries starting java (never the
+// program itself) up to attempts times, waiting backoff (doubled after
+// each attempt) between them, as long as the error is one of the
+// TransientErrors. Java is always started at least once (even when
+// attempts is less than 1).
+func ExecRetry(attempts int, backoff time.Duration, cmd ...string) error {
+	args, err := argv(cmd...)
+	if err != nil {
+		return err
+	}
+	if attempts < 1 {
+		attempts = 1
+	}
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var c *exec.Cmd
+		c, err = internal.Command(args...)
+		if err != nil {
+			return err
+		}
+		tail := captureStderr(c)
+		if err = c.Start(); err != nil {
+			if isTransient(err) {
+				continue
+			}
+			return err
+		}
+		err = c.Wait()
+		return execError(c, tail.String(), err)
+	}
+	return err
+}
+
+// Metrics (when set) is called after every ExecTimed with the argv that
+// was run, how long it took, and any error.
+var Metrics func(argv []string, d time.Duration, err error)
+
+// ExecTimed is the same as Exec but also returns the wall-clock time
+// taken (including JVM startup) and reports it to Metrics (if set).
+func ExecTimed(cmd ...string) (time.Duration, error) {
+	args, err := argv(cmd...)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	err = execArgv(args...)
+	d := time.Since(start)
+	if Metrics != nil {
+		Metrics(args, d, err)
+	}
+	return d, err
+}
+
+// ReadyTimeout is the longest ExecUntil waits for the java process to
+// write its ready line.
+var ReadyTimeout = time.Minute
+
+// ErrNotReady is returned by ExecUntil when the java process exits
+// before writing a line matching the pattern.
+var ErrNotReady = errors.New("java exited before ready")
+
+// ExecUntil is the same as ExecAsync but does not return until the java
+// process has written a line matching pattern to either its standard
+// output or error (usually a "started" or "listening" message from
+// a server). All output continues to be passed through to os.Stdout and
+// os.Stderr for as long as the process runs. If the process exits
+// without matching ErrNotReady is returned. If it takes longer than
+// ReadyTimeout the process is killed and ErrTimeout returned.
+func ExecUntil(pattern *regexp.Regexp, cmd ...string) (*Process, error) {
+	args, err := argv(cmd...)
+	if err != nil {
+		return nil, err
+	}
+	c, err := internal.Command(args...)
+	if err != nil {
+		return nil, err
+	}
+	outr, outw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	errr, errw, err := os.Pipe()
+	if err != nil {
+		outr.Close()
+		outw.Close()
+		return nil, err
+	}
+	c.Stdout, c.Stderr = outw, errw
+	err = c.Start()
+	outw.Close()
+	errw.Close()
+	if err != nil {
+		outr.Close()
+		errr.Close()
+		return nil, err
+	}
+
+	ready := make(chan struct{})
+	var once sync.Once
+	var wg sync.WaitGroup
+	watch := func(r *os.File, w io.Writer) {
+		defer wg.Done()
+		defer r.Close()
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			fmt.Fprintln(w, s.Text())
+			if pattern.MatchString(s.Text()) {
+				once.Do(func() { close(ready) })
+			}
+		}
+		io.Copy(w, r)
+	}
+	wg.Add(2)
+	go watch(outr, os.Stdout)
+	go watch(errr, os.Stderr)
+	done := make(chan struct{})
+	go func() { wg.Wait(); close(done) }()
+
+	p := &Process{Cmd: c}
+	timer := time.NewTimer(ReadyTimeout)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return p, nil
+	case <-done:
+		select {
+		case <-ready:
+			return p, nil
+		default:
+		}
+		if err := p.Wait(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotReady, err)
+		}
+		return nil, ErrNotReady
+	case <-timer.C:
+		p.Kill()
+		p.Wait()
+		return nil, fmt.Errorf("%w waiting for %q", ErrTimeout, pattern)
+	}
+}
+
+// ExecUsage is the same as Exec but also returns the state of the
+// exited java process (even when the error is from a non-zero exit)
+// from which the CPU time used (UserTime and SystemTime) can be
+// accounted. See MaxRSS for peak memory. The state is nil if java could
+// not be started.
+func ExecUsage(cmd ...string) (*os.ProcessState, error) {
+	args, err := argv(cmd...)
+	if err != nil {
+		return nil, err
+	}
+	c, err := internal.Command(args...)
+	if err != nil {
+		return nil, err
+	}
+	err = run(c)
+	return c.ProcessState, err
+}
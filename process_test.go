@@ -0,0 +1,230 @@
+package java_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleExecAsync() {
+
+	p, err := java.ExecAsync("-jar", "testdata/files.jar")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := p.Wait(); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// Hello, World!
+}
+
+func ExampleExecUntil() {
+
+	p, err := java.ExecUntil(regexp.MustCompile(`^Hello`), "-jar", "testdata/files.jar")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("ready")
+	p.Wait()
+
+	// Output:
+	// Hello, World!
+	// ready
+}
+
+func ExampleExecUsage() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/fakejava")
+	os.Setenv("PATH", bin)
+	stderr := os.Stderr
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stderr = stderr }()
+
+	state, err := java.ExecUsage("Main")
+	fmt.Println(err)
+	fmt.Println(state.ExitCode(), state.UserTime() >= 0, java.MaxRSS(state) > 0)
+
+	// Output:
+	// <nil>
+	// 0 true true
+}
+
+func ExampleCmd_Start() {
+
+	stderr := new(bytes.Buffer)
+	c := &java.Cmd{
+		Name:       "Main",
+		Executable: "testdata/fakejava/java",
+		LogPrefix:  "[svc] ",
+		Stderr:     stderr,
+	}
+	p, err := c.Start()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	p.Wait()
+	for _, line := range strings.Split(stderr.String(), "\n")[:2] {
+		fmt.Println(line)
+	}
+
+	// Output:
+	// [svc] Property settings:
+	// [svc]     file.encoding = UTF-8
+}
+
+func ExampleCmd_Start_shared() {
+
+	// the standard output and error of every process go to one buffer
+	buf := new(bytes.Buffer)
+	var procs []*java.Process
+	for _, name := range []string{"a", "b", "c"} {
+		c := &java.Cmd{
+			Name:       "Main",
+			Executable: "testdata/chattyjava/java",
+			LogPrefix:  "[" + name + "] ",
+			Stdout:     buf,
+			Stderr:     buf,
+		}
+		p, err := c.Start()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		procs = append(procs, p)
+	}
+	for _, p := range procs {
+		p.Wait()
+	}
+
+	line := regexp.MustCompile(`^\[[abc]\] (out|err) \d+$`)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var bad int
+	for _, it := range lines {
+		if !line.MatchString(it) {
+			bad++
+		}
+	}
+	fmt.Println(len(lines), bad)
+
+	// Output:
+	// 600 0
+}
+
+func ExampleProcess_Kill() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/slowjava")
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	p, err := java.ExecUntil(regexp.MustCompile(`^started`), "Main")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(p.Pid() > 0, p.Pid() == p.Cmd.Process.Pid)
+	fmt.Println(p.Kill())
+	err = p.Wait()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		status := exit.Sys().(syscall.WaitStatus)
+		fmt.Println(status.Signal() == syscall.SIGKILL)
+	}
+	fmt.Println(err)
+
+	// Output:
+	// started
+	// true true
+	// <nil>
+	// true
+	// signal: killed
+}
+
+func ExampleExecRetry() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	dir, _ := os.MkdirTemp("", "java-retry-")
+	defer os.RemoveAll(dir)
+	os.Setenv("PATH", dir)
+
+	// java still open for writing cannot be started (ETXTBSY) until it
+	// is closed, just as right after it has been extracted
+	exe := filepath.Join(dir, "java")
+	f, _ := os.OpenFile(exe, os.O_CREATE|os.O_WRONLY, 0755)
+	f.WriteString("#!/bin/sh\nprintf '%s\\n' started\n")
+
+	// never closed in time: 3 attempts wait 20ms and then 40ms
+	start := time.Now()
+	err := java.ExecRetry(3, 20*time.Millisecond, "Main")
+	fmt.Println(errors.Is(err, syscall.ETXTBSY), time.Since(start) >= 60*time.Millisecond)
+
+	// closed after 50ms: the third attempt (at 60ms) starts it
+	time.AfterFunc(50*time.Millisecond, func() { f.Close() })
+	fmt.Println(java.ExecRetry(5, 20*time.Millisecond, "Main"))
+
+	// always started at least once
+	fmt.Println(java.ExecRetry(0, 0, "Main"))
+	os.Setenv("PATH", "")
+	fmt.Println(java.ExecRetry(0, 0, "Main"))
+	os.Setenv("PATH", dir)
+
+	// not transient: never retried
+	os.WriteFile(exe, []byte("not a script"), 0755)
+	start = time.Now()
+	err = java.ExecRetry(3, time.Second, "Main")
+	fmt.Println(errors.Is(err, syscall.ENOEXEC), time.Since(start) < time.Second)
+
+	// Output:
+	// true true
+	// started
+	// <nil>
+	// started
+	// <nil>
+	// exec: "java": executable file not found in $PATH
+	// true true
+}
+
+func ExampleExecTimed() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	stderr := os.Stderr
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() { os.Stderr = stderr }()
+	defer func() { java.Metrics = nil }()
+
+	var took time.Duration
+	java.Metrics = func(argv []string, d time.Duration, err error) {
+		took = d
+		fmt.Println(filepath.Base(argv[0]), argv[1:], err)
+	}
+
+	bin, _ := filepath.Abs("testdata/fakejava")
+	os.Setenv("PATH", bin)
+	d, err := java.ExecTimed("-Xmx1g", "Main", "arg")
+	fmt.Println(err, d > 0, d == took)
+
+	bin, _ = filepath.Abs("testdata/failjava")
+	os.Setenv("PATH", bin)
+	d, err = java.ExecTimed("Main")
+	fmt.Println(err != nil, d == took)
+
+	// Output:
+	// java [-Xmx1g Main arg] <nil>
+	// <nil> true true
+	// java [Main] exit status 3: Exception in thread "main" java.lang.IllegalStateException: boom
+	// true true
+}
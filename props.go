@@ -0,0 +1,136 @@
+package java
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/rwxrob/java/internal"
+)
+
+// SystemProperties returns the system properties of the java on the
+// system path (as reported by java -XshowSettings:properties -version).
+// See ParseProperties.
+func SystemProperties() (map[string]string, error) { return systemProperties(nil) }
+
+// systemProperties is the same as SystemProperties but runs java with
+// the environment env (see exec.Cmd.Env) instead.
+func systemProperties(env []string) (map[string]string, error) {
+	cmd, err := internal.Command("java", "-XshowSettings:properties", "-version")
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = env
+	buf := new(bytes.Buffer)
+	cmd.Stdout, cmd.Stderr = buf, buf
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return ParseProperties(buf.String()), nil
+}
+
+// ParseProperties parses the output of java -XshowSettings:properties
+// into a map. Path-like values (java.class.path, java.library.path)
+// that java prints as multiple indented lines are joined back together
+// with the os.PathListSeparator.
+func ParseProperties(out string) map[string]string {
+	props := map[string]string{}
+	var last string
+	var in bool
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		switch {
+		case strings.HasPrefix(line, "Property settings:"):
+			in = true
+			continue
+		case !in:
+			continue
+		case strings.TrimSpace(line) == "":
+			in = false
+			continue
+		case strings.HasPrefix(line, "        ") && last != "":
+			val := strings.TrimSpace(line)
+			if props[last] != "" {
+				val = string(os.PathListSeparator) + val
+			}
+			props[last] += val
+			continue
+		}
+		key, val, found := strings.Cut(strings.TrimSpace(line), " =")
+		if !found {
+			continue
+		}
+		last = key
+		props[key] = strings.TrimPrefix(val, " ")
+	}
+	return props
+}
+
+// EffectiveClasspath returns the classpath (java.class.path) that the
+// java on the system path actually resolves (see SystemProperties)
+// with the CacheDir (and SystemCacheDir) added to the beginning of
+// CLASSPATH as they are when running anything. Use it to debug class
+// loading problems by comparing it with what was expected. The CLASSPATH
+// of the calling process is not changed.
+func EffectiveClasspath() (string, error) {
+	env := append(os.Environ(), "CLASSPATH="+classpath())
+	props, err := systemProperties(env)
+	if err != nil {
+		return "", err
+	}
+	return props["java.class.path"], nil
+}
+
+var (
+	propsCache   = map[string]map[string]string{}
+	propsCacheMu sync.Mutex
+)
+
+// cachedProperties returns the SystemProperties of the java on the
+// system path only running it once for each java executable.
+func cachedProperties() (map[string]string, error) {
+	path, err := exec.LookPath("java")
+	if err != nil {
+		return nil, err
+	}
+	propsCacheMu.Lock()
+	defer propsCacheMu.Unlock()
+	if props, has := propsCache[path]; has {
+		return props, nil
+	}
+	props, err := SystemProperties()
+	if err != nil {
+		return nil, err
+	}
+	propsCache[path] = props
+	return props, nil
+}
+
+// Charset returns the default charset (file.encoding) of the java on
+// the system path which is what Java programs use to read and write
+// text unless told otherwise. It is UTF-8 for JDK 18+ (see
+// Cmd.FileEncoding). The result is cached for each java executable.
+func Charset() (string, error) {
+	props, err := cachedProperties()
+	if err != nil {
+		return "", err
+	}
+	return props["file.encoding"], nil
+}
+
+// LineSeparator returns the line separator ("\n" or "\r\n") used by
+// the java on the system path (line.separator). The result is cached
+// for each java executable.
+func LineSeparator() (string, error) {
+	props, err := cachedProperties()
+	if err != nil {
+		return "", err
+	}
+	sep := strings.ReplaceAll(props["line.separator"], " ", "")
+	sep = strings.ReplaceAll(sep, `\r`, "\r")
+	return strings.ReplaceAll(sep, `\n`, "\n"), nil
+}
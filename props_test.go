@@ -0,0 +1,60 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleParseProperties() {
+
+	out, _ := os.ReadFile("testdata/settings.txt")
+	props := java.ParseProperties(string(out))
+
+	fmt.Println(len(props))
+	fmt.Printf("%q\n", props["java.class.path"])
+	fmt.Println(props["java.version"])
+	fmt.Println(props["java.library.path"])
+	fmt.Println(props["line.separator"])
+
+	// Output:
+	// 7
+	// ""
+	// 17.0.8
+	// /usr/java/packages/lib:/usr/lib/x86_64-linux-gnu/jni:/lib/x86_64-linux-gnu
+	// \n
+}
+
+func ExampleEffectiveClasspath() {
+
+	java.CacheDir = "testdata/tmpcache"
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	defer os.Setenv("CLASSPATH", os.Getenv("CLASSPATH"))
+	bin, _ := filepath.Abs("testdata/fakejava")
+	os.Setenv("PATH", bin)
+	os.Setenv("CLASSPATH", "lib/foo.jar")
+
+	fmt.Println(java.EffectiveClasspath())
+	fmt.Println(os.Getenv("CLASSPATH"))
+
+	// Output:
+	// testdata/tmpcache:lib/foo.jar <nil>
+	// lib/foo.jar
+}
+
+func ExampleCharset() {
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	bin, _ := filepath.Abs("testdata/fakejava")
+	os.Setenv("PATH", bin)
+
+	fmt.Println(java.Charset())
+	sep, err := java.LineSeparator()
+	fmt.Printf("%q %v\n", sep, err)
+
+	// Output:
+	// UTF-8 <nil>
+	// "\r\n" <nil>
+}
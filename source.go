@@ -0,0 +1,31 @@
+package java
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+var publicClass = regexp.MustCompile(
+	`(?m)^[ \t]*public\s+(?:(?:final|abstract|sealed|strictfp)\s+)*` +
+		`(?:class|interface|enum|record)\s+([\p{L}_$][\p{L}\p{N}_$]*)`)
+
+// SourceMainClass returns the name of the public top-level class (or
+// interface, enum, or record) declared in the Java source file at path
+// (which is resolved against the cache). This is a light regular
+// expression scan (not a parse) that ignores any declaration not at
+// the beginning of a line. When the name does not match the file name
+// older source launchers will fail with "class X is public, should be
+// declared in a file named X.java".
+func SourceMainClass(path string) (string, error) {
+	path, _ = resolve(path)
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	m := publicClass.FindSubmatch(buf)
+	if m == nil {
+		return "", fmt.Errorf("%v: no public class found", path)
+	}
+	return string(m[1]), nil
+}
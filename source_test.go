@@ -0,0 +1,20 @@
+package java_test
+
+import (
+	"fmt"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleSourceMainClass() {
+
+	name, err := java.SourceMainClass("testdata/javafiles/greeting.java")
+	fmt.Println(name, err)
+
+	_, err = java.SourceMainClass("testdata/javafiles/hello.java")
+	fmt.Println(err)
+
+	// Output:
+	// Greeting <nil>
+	// testdata/javafiles/hello.java: no public class found
+}
@@ -0,0 +1,88 @@
+package java
+
+import (
+	"os"
+	"sync"
+
+	"github.com/rwxrob/java/internal"
+)
+
+// TempDir is the directory in which all temporary files and
+// directories created by this package are placed (default
+// os.TempDir()). It must be writable and, since some of what is written
+// is executed, must not be mounted noexec (as /tmp sometimes is).
+var TempDir = os.TempDir()
+
+// UniqueSuffix returns a chronologically sortable suffix (the UTC time
+// down to the nanosecond without punctuation) suitable for making file
+// and directory names unique. The time comes from internal.Now which
+// tests within this module may freeze.
+func UniqueSuffix() string { return internal.Isonan() }
+
+var (
+	temps   []string
+	tempsMu sync.Mutex
+)
+
+// registerTemp records a temporary file or directory so that it will
+// be removed by Cleanup. Every function that creates temporary files or
+// directories must register them.
+func registerTemp(path string) {
+	tempsMu.Lock()
+	defer tempsMu.Unlock()
+	temps = append(temps, path)
+}
+
+// removeTemp removes (see os.RemoveAll) a temporary file or directory
+// registered with registerTemp and unregisters it so that Cleanup does
+// not hold on to it.
+func removeTemp(path string) error {
+	tempsMu.Lock()
+	defer tempsMu.Unlock()
+	for i, it := range temps {
+		if it == path {
+			temps = append(temps[:i], temps[i+1:]...)
+			break
+		}
+	}
+	return os.RemoveAll(path)
+}
+
+// createTemp is the same as os.CreateTemp but creates the file within
+// TempDir and registers it to be removed by Cleanup.
+func createTemp(pattern string) (*os.File, error) {
+	f, err := os.CreateTemp(TempDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	registerTemp(f.Name())
+	return f, nil
+}
+
+// mkdirTemp is the same as os.MkdirTemp but creates the directory within
+// TempDir and registers it to be removed by Cleanup.
+func mkdirTemp(pattern string) (string, error) {
+	dir, err := os.MkdirTemp(TempDir, pattern)
+	if err != nil {
+		return "", err
+	}
+	registerTemp(dir)
+	return dir, nil
+}
+
+// Cleanup removes all temporary files and directories created by this
+// package during the lifetime of the process. Removal of every one is
+// attempted but only the first error is returned. It is usually
+// deferred from main and is safe to call more than once.
+func Cleanup() error {
+	tempsMu.Lock()
+	defer tempsMu.Unlock()
+	var first error
+	for _, it := range temps {
+		if err := os.RemoveAll(it); err != nil && first == nil {
+			first = err
+		}
+	}
+	temps = nil
+	return first
+}
@@ -0,0 +1,56 @@
+package java_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/rwxrob/java"
+	"github.com/rwxrob/java/internal"
+)
+
+func ExampleUniqueSuffix() {
+
+	defer func(now func() time.Time) { internal.Now = now }(internal.Now)
+	internal.Now = func() time.Time {
+		return time.Date(2022, 3, 4, 5, 6, 7, 890000000, time.UTC)
+	}
+	fmt.Println(java.UniqueSuffix())
+
+	internal.Now = func() time.Time {
+		return time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	}
+	fmt.Println(java.UniqueSuffix())
+
+	// Output:
+	// 2022030405060789
+	// 20220304050607
+}
+
+func ExampleCleanup() {
+
+	defer func(dir string) { java.TempDir = dir }(java.TempDir)
+	java.TempDir, _ = os.MkdirTemp("", "java-cleanup-")
+	defer os.RemoveAll(java.TempDir)
+
+	java.WithTempCache(javafiles, "testdata/javafiles",
+		func(dir string) error {
+			_, err := os.Stat(dir)
+			fmt.Println(err)
+			fmt.Println(java.Cleanup())
+			_, err = os.Stat(dir)
+			fmt.Println(errors.Is(err, fs.ErrNotExist))
+			return nil
+		})
+
+	entries, _ := os.ReadDir(java.TempDir)
+	fmt.Println(len(entries), java.Cleanup())
+
+	// Output:
+	// <nil>
+	// <nil>
+	// true
+	// 0 <nil>
+}
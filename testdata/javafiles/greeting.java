@@ -0,0 +1,8 @@
+package greet;
+
+/* public class NotThisOne */
+public final class Greeting {
+    public static void main(String[] args) {
+        System.out.println("Greetings!");
+    }
+}
@@ -0,0 +1,11 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+package java
+
+import "os/exec"
+
+// WithUmask just calls fn since there is no umask on this system.
+func WithUmask(mask int, fn func() error) error { return fn() }
+
+// wrapUmask does nothing since there is no umask on this system.
+func wrapUmask(cmd *exec.Cmd, mask int) error { return nil }
@@ -0,0 +1,28 @@
+package java_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rwxrob/java"
+)
+
+func ExampleWithUmask() {
+
+	defer os.Remove("testdata/umask.txt")
+	java.WithUmask(0o077, func() error {
+		return os.WriteFile("testdata/umask.txt", nil, 0666)
+	})
+	info, _ := os.Stat("testdata/umask.txt")
+	fmt.Println(info.Mode().Perm())
+
+	c := &java.Cmd{Name: "Main", Executable: "testdata/showumask", Umask: 0o027}
+	out, err := c.Output()
+	fmt.Print(out)
+	fmt.Println(err)
+
+	// Output:
+	// -rw-------
+	// 0027
+	// <nil>
+}
@@ -0,0 +1,41 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package java
+
+import (
+	"fmt"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+var umaskMu sync.Mutex
+
+// WithUmask sets the file mode creation mask of the current process to
+// mask while fn is called (restoring the previous one afterward) so
+// that any files created by fn, including those created by java
+// processes started (and waited for) within it, are restricted by it.
+// Since the umask belongs to the whole process it also applies to any
+// files created by other goroutines at the same time. Calls to
+// WithUmask are serialized. Only Unix-like systems have a umask; on
+// others fn is just called.
+func WithUmask(mask int, fn func() error) error {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+	old := syscall.Umask(mask)
+	defer syscall.Umask(old)
+	return fn()
+}
+
+// wrapUmask changes cmd to run through the POSIX shell which sets the
+// umask to mask before replacing itself with the original command.
+func wrapUmask(cmd *exec.Cmd, mask int) error {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf(`umask %04o && exec "$@"`, mask)
+	cmd.Args = append([]string{"sh", "-c", script, "sh", cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = sh
+	return nil
+}
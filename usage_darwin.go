@@ -0,0 +1,18 @@
+package java
+
+import (
+	"os"
+	"syscall"
+)
+
+// MaxRSS returns the peak resident set size (maximum memory used) in
+// bytes of the exited process (see ExecUsage) or 0 if unknown.
+func MaxRSS(state *os.ProcessState) uint64 {
+	if state == nil {
+		return 0
+	}
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return uint64(ru.Maxrss) // already bytes on macOS
+	}
+	return 0
+}
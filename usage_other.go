@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package java
+
+import "os"
+
+// MaxRSS is not supported on this system and always returns 0.
+func MaxRSS(state *os.ProcessState) uint64 { return 0 }